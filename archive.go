@@ -0,0 +1,267 @@
+package waxpeer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	bucketHistory      = "history"
+	bucketOrderHistory = "order-history"
+	bucketSellOrders   = "sell-orders"
+	bucketBuyOrders    = "buy-orders"
+	bucketPrices       = "prices"
+
+	snapshotKeyLayout = "20060102T150405.000000000"
+)
+
+type PriceSnapshot struct {
+	Time  time.Time `json:"time"`
+	Game  Game      `json:"game"`
+	Items []*Price  `json:"items"`
+}
+
+type PricePoint struct {
+	Time  time.Time
+	Min   int64
+	Avg   int64
+	Max   int64
+	Count int64
+}
+
+// Archive persists history, orders and price snapshots fetched with Session in a Store
+type Archive struct {
+	Session *Session
+	Store   Store
+}
+
+func idKey(id int64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+// put stores v and reports whether the key is new or its value has changed
+func (a *Archive) put(bucket, key string, v interface{}) (bool, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	old, err := a.Store.Get(bucket, key)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(old, b) {
+		return false, nil
+	}
+	return true, a.Store.Put(bucket, key, b)
+}
+
+// SyncHistory fetches AccountHistory pages until a page contains no new or changed trades,
+// returns the number of stored trades
+func (a *Archive) SyncHistory() (int, error) {
+	var stored int
+	for skip := uint64(0); ; {
+		history, err := a.Session.AccountHistory(AccountHistoryConfig{Skip: skip})
+		if err != nil {
+			return stored, err
+		}
+		var changed int
+		for _, h := range history {
+			ok, err := a.put(bucketHistory, idKey(h.ID), h)
+			if err != nil {
+				return stored, err
+			}
+			if ok {
+				changed++
+			}
+		}
+		stored += changed
+		if len(history) == 0 || changed == 0 {
+			return stored, nil
+		}
+		skip += uint64(len(history))
+	}
+}
+
+// SyncOrderHistory fetches OrderHistory pages until a page contains no new or changed entries,
+// returns the number of stored entries
+func (a *Archive) SyncOrderHistory() (int, error) {
+	var stored int
+	for skip := uint64(0); ; {
+		history, err := a.Session.OrderHistory(OrderHistoryConfig{Skip: skip})
+		if err != nil {
+			return stored, err
+		}
+		var changed int
+		for _, h := range history {
+			ok, err := a.put(bucketOrderHistory, idKey(h.ID), h)
+			if err != nil {
+				return stored, err
+			}
+			if ok {
+				changed++
+			}
+		}
+		stored += changed
+		if len(history) == 0 || changed == 0 {
+			return stored, nil
+		}
+		skip += uint64(len(history))
+	}
+}
+
+// SyncSellOrders replaces the stored listings with the current SellOrders
+func (a *Archive) SyncSellOrders() (int, error) {
+	orders, err := a.Session.SellOrders()
+	if err != nil {
+		return 0, err
+	}
+	if err = a.Store.Clear(bucketSellOrders); err != nil {
+		return 0, err
+	}
+	for _, o := range orders {
+		if _, err = a.put(bucketSellOrders, idKey(int64(o.ItemID)), o); err != nil {
+			return 0, err
+		}
+	}
+	return len(orders), nil
+}
+
+// SyncBuyOrders replaces the stored buy orders with all pages of OrderOpen
+func (a *Archive) SyncBuyOrders() (int, error) {
+	var orders []*orderOpen
+	for skip := uint64(0); ; {
+		page, err := a.Session.OrderOpen(OrderOpenConfig{Skip: skip})
+		if err != nil {
+			return 0, err
+		}
+		orders = append(orders, page...)
+		if len(page) < 100 {
+			break
+		}
+		skip += uint64(len(page))
+	}
+	if err := a.Store.Clear(bucketBuyOrders); err != nil {
+		return 0, err
+	}
+	for _, o := range orders {
+		if _, err := a.put(bucketBuyOrders, idKey(o.ID), o); err != nil {
+			return 0, err
+		}
+	}
+	return len(orders), nil
+}
+
+// SnapshotPrices stores the current Prices as a snapshot
+func (a *Archive) SnapshotPrices(c PricesConfig) (*PriceSnapshot, error) {
+	items, err := a.Session.Prices(c)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &PriceSnapshot{Time: time.Now().UTC(), Game: c.Game, Items: items}
+	if _, err = a.put(bucketPrices, snapshot.Time.Format(snapshotKeyLayout), snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// History returns stored trades created between from and to, zero time - no bound
+func (a *Archive) History(from, to time.Time) ([]*accountHistory, error) {
+	var history []*accountHistory
+	err := a.Store.ForEach(bucketHistory, func(key string, value []byte) error {
+		var h accountHistory
+		if err := json.Unmarshal(value, &h); err != nil {
+			return err
+		}
+		if inRange(h.Created, from, to) {
+			history = append(history, &h)
+		}
+		return nil
+	})
+	return history, err
+}
+
+// OrderHistory returns stored buy order history created between from and to, zero time - no bound
+func (a *Archive) OrderHistory(from, to time.Time) ([]*orderHistory, error) {
+	var history []*orderHistory
+	err := a.Store.ForEach(bucketOrderHistory, func(key string, value []byte) error {
+		var h orderHistory
+		if err := json.Unmarshal(value, &h); err != nil {
+			return err
+		}
+		if inRange(h.Created, from, to) {
+			history = append(history, &h)
+		}
+		return nil
+	})
+	return history, err
+}
+
+// SellOrders returns listings stored by the last SyncSellOrders
+func (a *Archive) SellOrders() ([]*sellOrders, error) {
+	var orders []*sellOrders
+	err := a.Store.ForEach(bucketSellOrders, func(key string, value []byte) error {
+		var o sellOrders
+		if err := json.Unmarshal(value, &o); err != nil {
+			return err
+		}
+		orders = append(orders, &o)
+		return nil
+	})
+	return orders, err
+}
+
+// BuyOrders returns buy orders stored by the last SyncBuyOrders
+func (a *Archive) BuyOrders() ([]*orderOpen, error) {
+	var orders []*orderOpen
+	err := a.Store.ForEach(bucketBuyOrders, func(key string, value []byte) error {
+		var o orderOpen
+		if err := json.Unmarshal(value, &o); err != nil {
+			return err
+		}
+		orders = append(orders, &o)
+		return nil
+	})
+	return orders, err
+}
+
+// PriceSnapshots returns snapshots taken between from and to, zero time - no bound
+func (a *Archive) PriceSnapshots(from, to time.Time) ([]*PriceSnapshot, error) {
+	var snapshots []*PriceSnapshot
+	err := a.Store.ForEach(bucketPrices, func(key string, value []byte) error {
+		t, err := time.Parse(snapshotKeyLayout, key)
+		if err != nil || !inRange(t, from, to) {
+			return err
+		}
+		var snapshot PriceSnapshot
+		if err = json.Unmarshal(value, &snapshot); err != nil {
+			return err
+		}
+		snapshots = append(snapshots, &snapshot)
+		return nil
+	})
+	return snapshots, err
+}
+
+// PriceHistory returns prices of an item from the snapshots taken between from and to
+func (a *Archive) PriceHistory(name string, from, to time.Time) ([]*PricePoint, error) {
+	snapshots, err := a.PriceSnapshots(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var points []*PricePoint
+	for _, snapshot := range snapshots {
+		for _, p := range snapshot.Items {
+			if p.Name == name {
+				points = append(points, &PricePoint{Time: snapshot.Time, Min: p.Min, Avg: p.Avg, Max: p.Max, Count: p.Count})
+				break
+			}
+		}
+	}
+	return points, nil
+}
+
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
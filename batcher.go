@@ -0,0 +1,261 @@
+package waxpeer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Batcher combines single ItemAvailable and PricesName lookups of many goroutines into requests
+// of up to 100 items. Lookups are collected for Window or until 100 distinct items are pending
+type Batcher struct {
+	Session *Session
+	Window  time.Duration // default 50ms
+
+	mu        sync.Mutex
+	available *batchQueue
+	names     *batchQueue
+}
+
+type batchQueue struct {
+	fetch   func(keys []string) ([]interface{}, error)
+	keyOf   func(item interface{}) string
+	pending *batch
+}
+
+type batch struct {
+	keys    map[string]int // number of waiters by key
+	order   []string
+	waiters []*batchWaiter
+	timer   *time.Timer
+	flushed bool
+}
+
+type batchWaiter struct {
+	keys  []string
+	done  chan struct{}
+	items []interface{}
+	err   error
+}
+
+const batchMax = 100
+
+// ItemAvailable returns the items that are found, in the order of the response
+func (b *Batcher) ItemAvailable(ctx context.Context, ids ...ItemID) ([]*itemAvailable, error) {
+	b.init()
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
+	}
+	found, err := b.submit(ctx, b.available, keys)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*itemAvailable, len(found))
+	for i := range found {
+		items[i] = found[i].(*itemAvailable)
+	}
+	return items, nil
+}
+
+// PricesName returns the items of the names that are found
+func (b *Batcher) PricesName(ctx context.Context, names ...string) ([]*priceName, error) {
+	b.init()
+	found, err := b.submit(ctx, b.names, names)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*priceName, len(found))
+	for i := range found {
+		items[i] = found[i].(*priceName)
+	}
+	return items, nil
+}
+
+func (b *Batcher) init() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.available != nil {
+		return
+	}
+	b.available = &batchQueue{
+		fetch: func(keys []string) ([]interface{}, error) {
+			ids := make([]ItemID, 0, len(keys))
+			for _, key := range keys {
+				id, err := ParseItemID(key)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+			items, err := b.Session.ItemAvailable(&ids)
+			if err != nil {
+				return nil, err
+			}
+			found := make([]interface{}, len(items))
+			for i := range items {
+				found[i] = items[i]
+			}
+			return found, nil
+		},
+		keyOf: func(item interface{}) string {
+			return item.(*itemAvailable).ItemID.String()
+		},
+	}
+	b.names = &batchQueue{
+		fetch: func(keys []string) ([]interface{}, error) {
+			items, err := b.Session.PricesName(&keys)
+			if err != nil {
+				return nil, err
+			}
+			found := make([]interface{}, len(items))
+			for i := range items {
+				found[i] = items[i]
+			}
+			return found, nil
+		},
+		keyOf: func(item interface{}) string {
+			return item.(*priceName).Name
+		},
+	}
+}
+
+// submit adds the keys to the pending batch of q and waits for its response or ctx
+func (b *Batcher) submit(ctx context.Context, q *batchQueue, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if len(uniqueKeys(keys)) > batchMax {
+		return nil, max100Elements
+	}
+	w := &batchWaiter{keys: keys, done: make(chan struct{})}
+
+	b.mu.Lock()
+	if q.pending != nil && len(q.pending.keys)+newKeys(q.pending.keys, keys) > batchMax {
+		go b.flush(q, q.pending)
+		q.pending = nil
+	}
+	if q.pending == nil {
+		window := b.Window
+		if window == 0 {
+			window = 50 * time.Millisecond
+		}
+		p := &batch{keys: make(map[string]int)}
+		p.timer = time.AfterFunc(window, func() { b.flush(q, p) })
+		q.pending = p
+	}
+	p := q.pending
+	p.waiters = append(p.waiters, w)
+	for _, key := range keys {
+		if p.keys[key] == 0 {
+			p.order = append(p.order, key)
+		}
+		p.keys[key]++
+	}
+	if len(p.keys) == batchMax {
+		q.pending = nil
+		go b.flush(q, p)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return w.items, w.err
+	case <-ctx.Done():
+		b.cancel(q, p, w)
+		return nil, ctx.Err()
+	}
+}
+
+// cancel removes the waiter and its keys from a batch that is not sent yet
+func (b *Batcher) cancel(q *batchQueue, p *batch, w *batchWaiter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p.flushed {
+		return
+	}
+	for i := range p.waiters {
+		if p.waiters[i] == w {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			break
+		}
+	}
+	for _, key := range w.keys {
+		if p.keys[key]--; p.keys[key] <= 0 {
+			delete(p.keys, key)
+		}
+	}
+	order := p.order[:0]
+	for _, key := range p.order {
+		if p.keys[key] > 0 {
+			order = append(order, key)
+		}
+	}
+	p.order = order
+	if len(p.waiters) == 0 {
+		p.flushed = true
+		p.timer.Stop()
+		if q.pending == p {
+			q.pending = nil
+		}
+	}
+}
+
+func (b *Batcher) flush(q *batchQueue, p *batch) {
+	b.mu.Lock()
+	if q.pending == p {
+		q.pending = nil
+	}
+	if p.flushed {
+		b.mu.Unlock()
+		return
+	}
+	p.flushed = true
+	p.timer.Stop()
+	b.mu.Unlock()
+	runBatch(q, p)
+}
+
+// runBatch sends one request for the batch and hands every waiter the items of its keys
+func runBatch(q *batchQueue, p *batch) {
+	items, err := q.fetch(p.order)
+	for _, w := range p.waiters {
+		if err != nil {
+			w.err = err
+		} else {
+			want := make(map[string]bool, len(w.keys))
+			for _, key := range w.keys {
+				want[key] = true
+			}
+			for _, item := range items {
+				if want[q.keyOf(item)] {
+					w.items = append(w.items, item)
+				}
+			}
+		}
+		close(w.done)
+	}
+}
+
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// newKeys counts keys that are not pending yet
+func newKeys(pending map[string]int, keys []string) int {
+	var n int
+	for _, key := range uniqueKeys(keys) {
+		if pending[key] == 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,190 @@
+package waxpeer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// batchServer answers search-items-by-name and check-availability with one item per requested key
+// and records the keys of every request
+type batchServer struct {
+	mu       sync.Mutex
+	requests [][]string
+}
+
+func newBatchServer(t *testing.T) *batchServer {
+	b := &batchServer{}
+	fakeServer(t, func(ctx *fasthttp.RequestCtx) {
+		var keys []string
+		var items []interface{}
+		switch string(ctx.Path()) {
+		case "/v1/search-items-by-name":
+			for _, name := range ctx.QueryArgs().PeekMulti("names") {
+				keys = append(keys, string(name))
+				items = append(items, &priceName{Name: string(name), Price: 1000})
+			}
+		case "/v1/check-availability":
+			for _, key := range ctx.QueryArgs().PeekMulti("item_id") {
+				id, _ := ParseItemID(string(key))
+				keys = append(keys, string(key))
+				items = append(items, &itemAvailable{ItemID: id, Selling: true, Price: 1000})
+			}
+		}
+		b.mu.Lock()
+		b.requests = append(b.requests, keys)
+		b.mu.Unlock()
+		body, _ := json.Marshal(map[string]interface{}{"success": true, "items": items})
+		ctx.SetBody(body)
+	})
+	return b
+}
+
+func (b *batchServer) sent() [][]string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([][]string(nil), b.requests...)
+}
+
+func TestBatcherCombines(t *testing.T) {
+	server := newBatchServer(t)
+	b := &Batcher{Session: CreateSession("key"), Window: 50 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("item %d", i%25)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items, err := b.PricesName(context.Background(), name)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(items) != 1 || items[0].Name != name {
+				t.Errorf("%s: got %v", name, items)
+			}
+		}()
+	}
+	wg.Wait()
+	sent := server.sent()
+	if len(sent) != 1 || len(sent[0]) != 25 {
+		t.Fatalf("sent %d requests %v, want one with 25 distinct names", len(sent), sent)
+	}
+}
+
+func TestBatcherWindow(t *testing.T) {
+	newBatchServer(t)
+	b := &Batcher{Session: CreateSession("key"), Window: 100 * time.Millisecond}
+	start := time.Now()
+	items, err := b.ItemAvailable(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Fatalf("flushed after %v, want after the window", d)
+	}
+	if len(items) != 2 || items[0].ItemID != 1 || items[1].ItemID != 2 {
+		t.Fatalf("got %v", items)
+	}
+}
+
+func TestBatcherSplitsAt100(t *testing.T) {
+	server := newBatchServer(t)
+	b := &Batcher{Session: CreateSession("key"), Window: time.Second}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		id := ItemID(i + 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := b.ItemAvailable(context.Background(), id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("a full batch was sent after %v, want without waiting for the window", d)
+	}
+
+	for i := 0; i < 150; i++ {
+		id := ItemID(i + 1000)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := b.ItemAvailable(context.Background(), id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sent := server.sent()
+	sizes := make([]int, len(sent))
+	for i := range sent {
+		sizes[i] = len(sent[i])
+	}
+	sort.Ints(sizes)
+	if fmt.Sprint(sizes) != "[50 100 100]" {
+		t.Fatalf("sent requests of %v items, want [50 100 100]", sizes)
+	}
+	if _, err := b.ItemAvailable(context.Background(), make([]ItemID, 101)...); err != nil {
+		t.Fatalf("101 equal ids: %v", err)
+	}
+	ids := make([]ItemID, 101)
+	for i := range ids {
+		ids[i] = ItemID(i + 1)
+	}
+	if _, err := b.ItemAvailable(context.Background(), ids...); err != max100Elements {
+		t.Fatalf("101 distinct ids: got %v, want max100Elements", err)
+	}
+}
+
+func TestBatcherCancel(t *testing.T) {
+	server := newBatchServer(t)
+	b := &Batcher{Session: CreateSession("key"), Window: 100 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := b.PricesName(ctx, "cancelled", "shared")
+		cancelled <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	items, err := b.PricesName(context.Background(), "kept", "shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-cancelled; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("cancelled lookup: got %v, want context.DeadlineExceeded", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %v, want kept and shared", items)
+	}
+	sent := server.sent()
+	if len(sent) != 1 || fmt.Sprint(sent[0]) != "[shared kept]" {
+		t.Fatalf("sent %v, want one request without the cancelled name", sent)
+	}
+
+	// a batch whose only waiter gave up is not sent
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = b.PricesName(ctx, "alone"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if sent = server.sent(); len(sent) != 1 {
+		t.Fatalf("sent %v after the only waiter was cancelled", sent)
+	}
+}
@@ -0,0 +1,183 @@
+package waxpeer
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type CacheStats struct {
+	Hits    int64 // responses served from the cache
+	Misses  int64 // responses fetched from waxpeer
+	Shared  int64 // responses of in-flight requests shared with concurrent identical reads
+	Entries int   // cached responses, including expired ones not yet evicted
+}
+
+// Cache keeps successful responses of read endpoints for a TTL and coalesces concurrent identical reads
+// into one request. Responses do not depend on the api key, so one Cache can be shared by many sessions
+type Cache struct {
+	TTL map[string]time.Duration // ttl by endpoint, ex: "prices", endpoints not listed are not cached. Set before use
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	calls   map[string]*cacheCall
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	endpoint string
+	resp     *Response
+	expires  time.Time
+}
+
+type cacheCall struct {
+	key     string
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	resp    *Response
+	err     error
+}
+
+// NewCache caches Prices and PricesName for 30 seconds and PricesSteam for 5 minutes
+func NewCache() *Cache {
+	return &Cache{TTL: map[string]time.Duration{
+		endpointName(steamPrices):            30 * time.Second,
+		endpointName(steamSearchItemsByName): 30 * time.Second,
+		endpointName(steamGetSteamItems):     5 * time.Minute,
+	}}
+}
+
+// WithCache serves read requests from c
+func WithCache(c *Cache) Option {
+	return func(s *Session) {
+		s.cache = c
+	}
+}
+
+// Invalidate drops the cached responses of the endpoint, ex: "prices"
+func (c *Cache) Invalidate(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if e.endpoint == endpoint {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Clear drops all cached responses
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+// get returns the cached response or calls fetch, concurrent calls with the same key wait for one fetch.
+// The fetch does not depend on the context of any caller, it is cancelled when every caller has given up.
+// Only 2xx responses with success: true are stored
+func (c *Cache) get(ctx context.Context, endpoint string, params url.Values, fetch func(ctx context.Context) (*Response, error)) (*Response, error) {
+	ttl := c.TTL[endpoint]
+	if ttl <= 0 {
+		return fetch(ctx)
+	}
+	key := cacheKey(endpoint, params)
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && time.Now().Before(e.expires) {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return e.resp, nil
+	}
+	if call, ok := c.calls[key]; ok {
+		c.stats.Shared++
+		call.waiters++
+		c.mu.Unlock()
+		return c.wait(ctx, call)
+	}
+	if c.calls == nil {
+		c.calls = make(map[string]*cacheCall)
+	}
+	fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	call := &cacheCall{key: key, done: make(chan struct{}), cancel: cancel, waiters: 1}
+	c.calls[key] = call
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		resp, err := fetch(fetchCtx)
+		store := err == nil && cacheable(resp)
+
+		c.mu.Lock()
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+		if store {
+			c.put(key, &cacheEntry{endpoint: endpoint, resp: resp, expires: time.Now().Add(ttl)})
+		}
+		c.mu.Unlock()
+		call.resp, call.err = resp, err
+		close(call.done)
+	}()
+	return c.wait(ctx, call)
+}
+
+// wait returns the result of the call or ctx.Err(), the last caller to give up cancels the fetch
+func (c *Cache) wait(ctx context.Context, call *cacheCall) (*Response, error) {
+	select {
+	case <-call.done:
+		return call.resp, call.err
+	case <-ctx.Done():
+	}
+	c.mu.Lock()
+	if call.waiters--; call.waiters == 0 {
+		if c.calls[call.key] == call {
+			delete(c.calls, call.key)
+		}
+		call.cancel()
+	}
+	c.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+func cacheable(resp *Response) bool {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false
+	}
+	success, _ := resp.Outcome()
+	return success
+}
+
+// put stores the entry and evicts expired ones, c.mu must be held
+func (c *Cache) put(key string, e *cacheEntry) {
+	if c.entries == nil {
+		c.entries = make(map[string]*cacheEntry)
+	}
+	now := time.Now()
+	for k, old := range c.entries {
+		if !now.Before(old.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = e
+}
+
+// cacheKey identifies a request by endpoint and params, the api key is not part of it
+func cacheKey(endpoint string, params url.Values) string {
+	safe := make(url.Values, len(params))
+	for k, v := range params {
+		if k != "api" {
+			safe[k] = v
+		}
+	}
+	return endpoint + "?" + safe.Encode()
+}
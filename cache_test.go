@@ -0,0 +1,132 @@
+package waxpeer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// slowPrices answers prices requests with body after delay and counts them
+func slowPrices(t *testing.T, delay time.Duration) *int64 {
+	body := pricesBody(t, 10)
+	var requests int64
+	fakeServer(t, func(ctx *fasthttp.RequestCtx) {
+		atomic.AddInt64(&requests, 1)
+		time.Sleep(delay)
+		ctx.SetBody(body)
+	})
+	return &requests
+}
+
+func TestCacheShared(t *testing.T) {
+	requests := slowPrices(t, 50*time.Millisecond)
+	cache := NewCache()
+	s := CreateSession("key", WithCache(cache))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Prices(PricesConfig{Game: GameCSGO}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := s.Prices(PricesConfig{Game: GameCSGO}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(requests); n != 1 {
+		t.Fatalf("sent %d requests, want 1", n)
+	}
+	stats := cache.Stats()
+	if stats.Misses != 1 || stats.Shared+stats.Hits != 10 || stats.Hits == 0 {
+		t.Fatalf("stats %+v, want 1 miss and 10 shared or hits", stats)
+	}
+}
+
+func TestCacheWaiterContext(t *testing.T) {
+	slowPrices(t, 300*time.Millisecond)
+	s := CreateSession("key", WithCache(NewCache()))
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := s.Prices(PricesConfig{Game: GameCSGO})
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := s.WithContext(ctx).Prices(PricesConfig{Game: GameCSGO})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 150*time.Millisecond {
+		t.Fatalf("waiter returned after %v, want soon after its deadline", d)
+	}
+	if err = <-first; err != nil {
+		t.Fatalf("first caller: %v", err)
+	}
+}
+
+func TestCacheFirstCallerCancel(t *testing.T) {
+	slowPrices(t, 100*time.Millisecond)
+	s := CreateSession("key", WithCache(NewCache()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := s.WithContext(ctx).Prices(PricesConfig{Game: GameCSGO})
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := s.Prices(PricesConfig{Game: GameCSGO})
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first caller: got %v, want context.Canceled", err)
+	}
+	if err := <-second; err != nil {
+		t.Fatalf("second caller: %v", err)
+	}
+}
+
+func TestCacheStoresOnlySuccess(t *testing.T) {
+	success := pricesBody(t, 10)
+	responses := []struct {
+		status int
+		body   string
+	}{
+		{fasthttp.StatusInternalServerError, `{"success":true,"items":[]}`},
+		{fasthttp.StatusTooManyRequests, `{"success":true,"items":[]}`},
+		{fasthttp.StatusOK, `{"success": false, "msg": "try later"}`},
+		{fasthttp.StatusOK, string(success)},
+	}
+	var requests int64
+	fakeServer(t, func(ctx *fasthttp.RequestCtx) {
+		r := responses[atomic.AddInt64(&requests, 1)-1]
+		ctx.SetStatusCode(r.status)
+		ctx.SetBodyString(r.body)
+	})
+	s := CreateSession("key", WithCache(NewCache()))
+	for i := 0; i < len(responses)+2; i++ {
+		s.Prices(PricesConfig{Game: GameCSGO})
+	}
+	if n := atomic.LoadInt64(&requests); n != int64(len(responses)) {
+		t.Fatalf("sent %d requests, want %d: only the last response is cached", n, len(responses))
+	}
+}
@@ -0,0 +1,336 @@
+package waxpeer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CatalogItem struct {
+	Name       string
+	RuName     string
+	Type       string // ex: Rifle, Knife, Sticker
+	Collection string
+	Game       Game
+	Average    int64 // average steam price, 1$ = 1000
+	ItemName   ItemName
+}
+
+// Catalog is a local copy of the PricesSteam item list indexed by name, collection and type
+type Catalog struct {
+	Session  *Session
+	Games    []Game        // games loaded by Refresh and Run, default GameCSGO
+	MaxAge   time.Duration // lookups reload games older than this, 0 - load once
+	Interval time.Duration // delay between refreshes in Run, default 1 hour
+	OnError  func(err error)
+
+	mu      sync.RWMutex
+	games   map[Game]*catalogIndex
+	loading map[Game]*catalogLoad
+}
+
+// catalogLoad is a PricesSteam download shared by concurrent loads of one game
+type catalogLoad struct {
+	done chan struct{}
+	n    int
+	err  error
+}
+
+type catalogIndex struct {
+	loaded       time.Time
+	items        []*CatalogItem
+	keys         []catalogKeys // search keys of items, by position
+	byName       map[string]*CatalogItem
+	byCollection map[string][]*CatalogItem
+	byType       map[string][]*CatalogItem
+}
+
+// Run refreshes the catalog every Interval until ctx is done, ctx also cancels a running refresh
+func (c *Catalog) Run(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	session := c.Session.WithContext(ctx)
+	for {
+		if err := c.refresh(session); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if c.OnError == nil {
+				return err
+			}
+			c.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh loads all Games
+func (c *Catalog) Refresh() error {
+	return c.refresh(c.Session)
+}
+
+func (c *Catalog) refresh(session *Session) error {
+	games := c.Games
+	if len(games) == 0 {
+		games = []Game{GameCSGO}
+	}
+	for _, game := range games {
+		if _, err := c.load(session, game); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load fetches the item list of the game and replaces the cached one, returns the number of items
+func (c *Catalog) Load(game Game) (int, error) {
+	return c.load(c.Session, game)
+}
+
+// load downloads the game, concurrent loads of the same game wait for one download
+func (c *Catalog) load(session *Session, game Game) (int, error) {
+	c.mu.Lock()
+	if l, ok := c.loading[game]; ok {
+		c.mu.Unlock()
+		<-l.done
+		return l.n, l.err
+	}
+	if c.loading == nil {
+		c.loading = make(map[Game]*catalogLoad)
+	}
+	l := &catalogLoad{done: make(chan struct{})}
+	c.loading[game] = l
+	c.mu.Unlock()
+
+	l.n, l.err = c.fetch(session, game)
+	c.mu.Lock()
+	delete(c.loading, game)
+	c.mu.Unlock()
+	close(l.done)
+	return l.n, l.err
+}
+
+func (c *Catalog) fetch(session *Session, game Game) (int, error) {
+	items, err := session.PricesSteam(game)
+	if err != nil {
+		return 0, err
+	}
+	index := &catalogIndex{
+		loaded:       time.Now(),
+		items:        make([]*CatalogItem, 0, len(items)),
+		keys:         make([]catalogKeys, 0, len(items)),
+		byName:       make(map[string]*CatalogItem, len(items)),
+		byCollection: make(map[string][]*CatalogItem),
+		byType:       make(map[string][]*CatalogItem),
+	}
+	for _, item := range items {
+		i := &CatalogItem{
+			Name:       item.Name,
+			RuName:     catalogString(item.RuName),
+			Type:       catalogString(item.Type),
+			Collection: catalogString(item.Collection),
+			Game:       game,
+			Average:    item.Average,
+			ItemName:   ParseItemName(item.Name),
+		}
+		keys := catalogKeys{name: catalogKey(i.Name), ruName: catalogKey(i.RuName)}
+		index.items = append(index.items, i)
+		index.keys = append(index.keys, keys)
+		index.byName[keys.name] = i
+		if i.Collection != "" {
+			key := catalogKey(i.Collection)
+			index.byCollection[key] = append(index.byCollection[key], i)
+		}
+		if i.Type != "" {
+			key := catalogKey(i.Type)
+			index.byType[key] = append(index.byType[key], i)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.games == nil {
+		c.games = make(map[Game]*catalogIndex)
+	}
+	c.games[game] = index
+	return len(index.items), nil
+}
+
+// Loaded returns the time the game was last loaded, zero time - never
+func (c *Catalog) Loaded(game Game) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if index, ok := c.games[game]; ok {
+		return index.loaded
+	}
+	return time.Time{}
+}
+
+// index returns the cached game, loading it if it is missing or older than MaxAge
+func (c *Catalog) index(game Game) (*catalogIndex, error) {
+	c.mu.RLock()
+	index, ok := c.games[game]
+	c.mu.RUnlock()
+	if ok && (c.MaxAge == 0 || time.Since(index.loaded) < c.MaxAge) {
+		return index, nil
+	}
+	if _, err := c.load(c.Session, game); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.games[game], nil
+}
+
+// Items returns all items of the game
+func (c *Catalog) Items(game Game) ([]*CatalogItem, error) {
+	index, err := c.index(game)
+	if err != nil {
+		return nil, err
+	}
+	return index.items, nil
+}
+
+// Item returns the item by name or nil, the name is normalized with NormalizeItemName
+func (c *Catalog) Item(game Game, name string) (*CatalogItem, error) {
+	index, err := c.index(game)
+	if err != nil {
+		return nil, err
+	}
+	if item, ok := index.byName[catalogKey(name)]; ok {
+		return item, nil
+	}
+	return index.byName[catalogKey(NormalizeItemName(name))], nil
+}
+
+// Collection returns items of the collection, ex: The Dust 2 Collection
+func (c *Catalog) Collection(game Game, collection string) ([]*CatalogItem, error) {
+	index, err := c.index(game)
+	if err != nil {
+		return nil, err
+	}
+	return index.byCollection[catalogKey(collection)], nil
+}
+
+// Type returns items of the type, ex: Rifle
+func (c *Catalog) Type(game Game, typ string) ([]*CatalogItem, error) {
+	index, err := c.index(game)
+	if err != nil {
+		return nil, err
+	}
+	return index.byType[catalogKey(typ)], nil
+}
+
+// Search finds items by english or russian name. Exact matches go first, then prefix matches,
+// matches at the start of a word, substring matches and finally fuzzy matches where the query
+// letters appear in the name in order. limit 0 - all matches
+func (c *Catalog) Search(game Game, query string, limit int) ([]*CatalogItem, error) {
+	index, err := c.index(game)
+	if err != nil {
+		return nil, err
+	}
+	query = catalogKey(query)
+	if query == "" {
+		return nil, nil
+	}
+	type match struct {
+		item  *CatalogItem
+		score int
+	}
+	var matches []match
+	for i, item := range index.items {
+		keys := index.keys[i]
+		score := searchScore(keys.name, query)
+		if keys.ruName != "" {
+			if ru := searchScore(keys.ruName, query); ru < score {
+				score = ru
+			}
+		}
+		if score != scoreNone {
+			matches = append(matches, match{item: item, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return matches[i].item.Name < matches[j].item.Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	items := make([]*CatalogItem, len(matches))
+	for i, m := range matches {
+		items[i] = m.item
+	}
+	return items, nil
+}
+
+const (
+	scoreExact = iota
+	scorePrefix
+	scoreWordPrefix
+	scoreSubstring
+	scoreFuzzy
+	scoreNone
+)
+
+func searchScore(name, query string) int {
+	switch {
+	case name == query:
+		return scoreExact
+	case strings.HasPrefix(name, query):
+		return scorePrefix
+	case strings.Contains(name, " "+query):
+		return scoreWordPrefix
+	case strings.Contains(name, query):
+		return scoreSubstring
+	case subsequence(name, query):
+		return scoreFuzzy
+	}
+	return scoreNone
+}
+
+// subsequence reports whether the letters of query appear in s in order
+func subsequence(s, query string) bool {
+	q := []rune(strings.ReplaceAll(query, " ", ""))
+	i := 0
+	for _, r := range s {
+		if i < len(q) && r == q[i] {
+			i++
+		}
+	}
+	return i == len(q)
+}
+
+// catalogString converts loosely typed fields of steam items, null is empty
+func catalogString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+type catalogKeys struct {
+	name   string
+	ruName string
+}
+
+// catalogKey lowercases and normalizes names for lookups
+func catalogKey(s string) string {
+	s = strings.Join(strings.Fields(nameReplacer.Replace(s)), " ")
+	return strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const apiKeyEnv = "WAXPEER_API_KEY"
+
+type config struct {
+	ApiKey string `json:"api_key"`
+}
+
+// loadApiKey reads the api key from WAXPEER_API_KEY or from the config file,
+// by default $XDG_CONFIG_HOME/waxpeer/config.json
+func loadApiKey(path string) (string, error) {
+	if key := os.Getenv(apiKeyEnv); key != "" {
+		return key, nil
+	}
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, "waxpeer", "config.json")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.New("api key not found: set " + apiKeyEnv + " or create " + path)
+		}
+		return "", err
+	}
+	var c config
+	if err = json.Unmarshal(b, &c); err != nil {
+		return "", err
+	}
+	if c.ApiKey == "" {
+		return "", errors.New("api_key is empty in " + path)
+	}
+	return c.ApiKey, nil
+}
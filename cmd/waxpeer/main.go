@@ -0,0 +1,351 @@
+// Command waxpeer is a command-line client for the waxpeer API.
+//
+// Usage:
+//
+//	waxpeer [-config file] [-o table|json|csv] <command> [subcommand] [flags]
+//
+// The api key is read from WAXPEER_API_KEY or from the config file
+// ({"api_key": "..."}, by default $XDG_CONFIG_HOME/waxpeer/config.json).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	waxpeer "github.com/1makarov/go-waxpeer"
+)
+
+const usage = `usage: waxpeer [-config file] [-o table|json|csv] <command> [flags]
+
+commands:
+  account info
+  history         [-skip n] [-partner p] [-token t]
+  orders list     [-name n] [-skip n]
+  orders create   -name n -price p -amount a
+  orders edit     -id id -price p -amount a
+  orders remove   id...
+  orders remove-all
+  orders history  [-skip n]
+  sell list
+  sell inventory  [-game csgo] [-skip n]
+  sell create     item_id=price...
+  sell edit       item_id=price...
+  sell remove     item_id...
+  sell remove-all
+  buy id          -item id -price p -partner p -token t [-project id]
+  buy name        -name n -price p -partner p -token t [-project id]
+  prices          [-game csgo] [-search s] [-min p] [-max p]
+  transfer        -steamid id -amount a
+`
+
+type command func(s *waxpeer.Session, args []string) (interface{}, error)
+
+var commands = map[string]command{
+	"account info":      accountInfo,
+	"history":           history,
+	"orders list":       ordersList,
+	"orders create":     ordersCreate,
+	"orders edit":       ordersEdit,
+	"orders remove":     ordersRemove,
+	"orders remove-all": ordersRemoveAll,
+	"orders history":    ordersHistory,
+	"sell list":         sellList,
+	"sell inventory":    sellInventory,
+	"sell create":       sellCreate,
+	"sell edit":         sellEdit,
+	"sell remove":       sellRemove,
+	"sell remove-all":   sellRemoveAll,
+	"buy id":            buyID,
+	"buy name":          buyName,
+	"prices":            prices,
+	"transfer":          transfer,
+}
+
+type result struct {
+	Result string `json:"result"`
+}
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	configPath := flag.String("config", "", "path to the config file")
+	output := flag.String("o", "table", "output format: table, json or csv")
+	flag.Parse()
+
+	cmd, args, ok := lookup(flag.Args())
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+	key, err := loadApiKey(*configPath)
+	if err != nil {
+		fatal(err)
+	}
+	v, err := cmd(waxpeer.CreateSession(key), args)
+	if err != nil {
+		fatal(err)
+	}
+	if v == nil {
+		v = result{Result: "ok"}
+	}
+	if err = write(os.Stdout, *output, v); err != nil {
+		fatal(err)
+	}
+}
+
+func lookup(args []string) (command, []string, bool) {
+	if len(args) >= 2 {
+		if cmd, ok := commands[args[0]+" "+args[1]]; ok {
+			return cmd, args[2:], true
+		}
+	}
+	if len(args) >= 1 {
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd, args[1:], true
+		}
+	}
+	return nil, nil, false
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "waxpeer:", err)
+	os.Exit(1)
+}
+
+func newFlags(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ExitOnError)
+}
+
+func accountInfo(s *waxpeer.Session, args []string) (interface{}, error) {
+	return s.AccountInformation()
+}
+
+func history(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("history")
+	skip := f.Uint64("skip", 0, "skip trades")
+	partner := f.String("partner", "", "partner from tradelink or steamid32")
+	token := f.String("token", "", "token from tradelink")
+	f.Parse(args)
+	return s.AccountHistory(waxpeer.AccountHistoryConfig{Skip: *skip, Partner: *partner, Token: *token})
+}
+
+func ordersList(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("orders list")
+	name := f.String("name", "", "item name")
+	skip := f.Uint64("skip", 0, "skip orders")
+	f.Parse(args)
+	return s.OrderOpen(waxpeer.OrderOpenConfig{Name: *name, Skip: *skip})
+}
+
+func ordersCreate(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("orders create")
+	name := f.String("name", "", "item name")
+	price := f.Uint64("price", 0, "max price, 1$ = 1000")
+	amount := f.Uint64("amount", 1, "amount of items")
+	f.Parse(args)
+	resp, err := s.OrderCreate(waxpeer.OrderCreateConfig{Name: *name, Price: *price, Amount: *amount})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func ordersEdit(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("orders edit")
+	id := f.Uint64("id", 0, "buy order id")
+	price := f.Uint64("price", 0, "new price, 1$ = 1000")
+	amount := f.Uint64("amount", 0, "new amount")
+	f.Parse(args)
+	resp, err := s.OrderEdit(waxpeer.OrderEditConfig{ID: *id, Price: *price, Amount: *amount})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func ordersRemove(s *waxpeer.Session, args []string) (interface{}, error) {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.OrderRemove(&ids)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func ordersRemoveAll(s *waxpeer.Session, args []string) (interface{}, error) {
+	resp, err := s.OrderRemoveAll()
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func ordersHistory(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("orders history")
+	skip := f.Uint64("skip", 0, "skip trades")
+	f.Parse(args)
+	return s.OrderHistory(waxpeer.OrderHistoryConfig{Skip: *skip})
+}
+
+func sellList(s *waxpeer.Session, args []string) (interface{}, error) {
+	return s.SellOrders()
+}
+
+func sellInventory(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("sell inventory")
+	game := gameFlag(waxpeer.GameCSGO)
+	f.Var(&game, "game", "csgo, dota2, rust, tf2 or app id")
+	skip := f.Uint64("skip", 0, "skip items")
+	f.Parse(args)
+	return s.SellItems(waxpeer.SellItemsConfig{Game: waxpeer.Game(game), Skip: *skip})
+}
+
+func sellCreate(s *waxpeer.Session, args []string) (interface{}, error) {
+	items, err := parseSellItems(args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Sell(&items)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func sellEdit(s *waxpeer.Session, args []string) (interface{}, error) {
+	items, err := parseSellItems(args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.SellEdit(&items)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func sellRemove(s *waxpeer.Session, args []string) (interface{}, error) {
+	ids, err := parseItemIDs(args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.SellRemove(&ids)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func sellRemoveAll(s *waxpeer.Session, args []string) (interface{}, error) {
+	resp, err := s.SellRemoveAll()
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func buyID(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("buy id")
+	item := f.Uint64("item", 0, "item id")
+	price := f.Uint64("price", 0, "item price, 1$ = 1000")
+	partner := f.String("partner", "", "partner from tradelink")
+	token := f.String("token", "", "token from tradelink")
+	project := f.String("project", "", "your unique trade id")
+	f.Parse(args)
+	return nil, s.BuyID(waxpeer.BuyIDConfig{ItemId: waxpeer.ItemID(*item), Price: *price, Partner: *partner, Token: *token, ProjectId: *project})
+}
+
+func buyName(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("buy name")
+	name := f.String("name", "", "item name")
+	price := f.Uint64("price", 0, "item price, 1$ = 1000")
+	partner := f.String("partner", "", "partner from tradelink")
+	token := f.String("token", "", "token from tradelink")
+	project := f.String("project", "", "your unique trade id")
+	f.Parse(args)
+	return nil, s.BuyName(waxpeer.BuyNameConfig{Name: *name, Price: *price, Partner: *partner, Token: *token, ProjectId: *project})
+}
+
+func prices(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("prices")
+	game := gameFlag(waxpeer.GameCSGO)
+	f.Var(&game, "game", "csgo, dota2, rust, tf2 or app id")
+	search := f.String("search", "", "search by name")
+	min := f.Uint64("min", 0, "min price, 1$ = 1000")
+	max := f.Uint64("max", 0, "max price, 1$ = 1000")
+	f.Parse(args)
+	return s.Prices(waxpeer.PricesConfig{Game: waxpeer.Game(game), Search: *search, MinPrice: *min, MaxPrice: *max})
+}
+
+func transfer(s *waxpeer.Session, args []string) (interface{}, error) {
+	f := newFlags("transfer")
+	steamID := f.Uint64("steamid", 0, "steamid64 of the receiver")
+	amount := f.Uint64("amount", 0, "amount, 1$ = 1000")
+	f.Parse(args)
+	return nil, s.AccountTransfer(waxpeer.AccountTransferConfig{SteamId: *steamID, Amount: *amount})
+}
+
+// gameFlag accepts a game slug or a steam app id
+type gameFlag waxpeer.Game
+
+func (g *gameFlag) String() string {
+	return string(*g)
+}
+
+func (g *gameFlag) Set(v string) error {
+	game, err := waxpeer.ParseGame(v)
+	if err != nil {
+		return err
+	}
+	*g = gameFlag(game)
+	return nil
+}
+
+func parseIDs(args []string) ([]uint64, error) {
+	ids := make([]uint64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseItemIDs(args []string) ([]waxpeer.ItemID, error) {
+	ids := make([]waxpeer.ItemID, 0, len(args))
+	for _, arg := range args {
+		id, err := waxpeer.ParseItemID(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid item id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseSellItems(args []string) ([]waxpeer.SellItemConfig, error) {
+	items := make([]waxpeer.SellItemConfig, 0, len(args))
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid item %q, use item_id=price", arg)
+		}
+		id, err := waxpeer.ParseItemID(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid item id %q", parts[0])
+		}
+		price, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price %q", parts[1])
+		}
+		items = append(items, waxpeer.SellItemConfig{ItemID: id, Price: price})
+	}
+	return items, nil
+}
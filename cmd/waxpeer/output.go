@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// write prints v as a table, json or csv, v is a struct, a slice of structs or pointers to them
+func write(w io.Writer, format string, v interface{}) error {
+	if format == "json" {
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(v)
+	}
+	header, rows := flatten(v)
+	switch format {
+	case "csv":
+		c := csv.NewWriter(w)
+		if err := c.Write(header); err != nil {
+			return err
+		}
+		if err := c.WriteAll(rows); err != nil {
+			return err
+		}
+		return c.Error()
+	case "table", "":
+		t := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(t, strings.ToUpper(strings.Join(header, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(t, strings.Join(row, "\t"))
+		}
+		return t.Flush()
+	}
+	return fmt.Errorf("unknown output format %q, use table, json or csv", format)
+}
+
+func flatten(v interface{}) ([]string, [][]string) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	var items []reflect.Value
+	if rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, reflect.Indirect(rv.Index(i)))
+		}
+	} else if rv.IsValid() {
+		items = append(items, rv)
+	}
+	var header []string
+	var rows [][]string
+	for i, item := range items {
+		var row []string
+		columns(item, "", false, func(name, value string) {
+			if i == 0 {
+				header = append(header, name)
+			}
+			row = append(row, value)
+		})
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// columns adds every exported field of v, nested structs are flattened with dotted names,
+// empty is set for fields of nil struct pointers so that all rows have the same columns
+func columns(v reflect.Value, prefix string, empty bool, add func(name, value string)) {
+	if v.Kind() != reflect.Struct || v.Type() == reflect.TypeOf(time.Time{}) {
+		value := ""
+		if !empty {
+			value = format(v)
+		}
+		add(strings.TrimSuffix(prefix, "."), value)
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = strings.ToLower(f.Name)
+		}
+		fv, fempty := v.Field(i), empty
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				fv, fempty = reflect.Zero(fv.Type().Elem()), true
+			} else {
+				fv = fv.Elem()
+			}
+		}
+		columns(fv, prefix+name+".", fempty, add)
+	}
+}
+
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return format(v.Elem())
+	case reflect.Slice:
+		parts := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts = append(parts, format(v.Index(i)))
+		}
+		return strings.Join(parts, " ")
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,229 @@
+package waxpeer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider returns the waxpeer api key, it is consulted before every request
+type CredentialProvider interface {
+	ApiKey(ctx context.Context) (string, error)
+}
+
+// CredentialInvalidator is implemented by providers that can reload the key
+// after waxpeer rejected it, ex: FileCredentials
+type CredentialInvalidator interface {
+	Invalidate()
+}
+
+// StaticCredentials always returns the same key
+type StaticCredentials string
+
+func (c StaticCredentials) ApiKey(ctx context.Context) (string, error) {
+	return string(c), nil
+}
+
+// EnvCredentials reads the key from the environment variable on every request, ex: EnvCredentials("WAXPEER_API_KEY")
+type EnvCredentials string
+
+func (c EnvCredentials) ApiKey(ctx context.Context) (string, error) {
+	key := os.Getenv(string(c))
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s is empty", string(c))
+	}
+	return key, nil
+}
+
+// CredentialFunc allows to use an ordinary function as a CredentialProvider, ex: to read the key from a secret store
+type CredentialFunc func(ctx context.Context) (string, error)
+
+func (f CredentialFunc) ApiKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// FileCredentials reads the key from a file and reloads it when the file changes
+type FileCredentials struct {
+	Path   string
+	Reload time.Duration // how often the file is checked for changes, 0 - only after Invalidate
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	checked time.Time
+}
+
+func (c *FileCredentials) ApiKey(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.key != "" && (c.Reload == 0 || time.Since(c.checked) < c.Reload) {
+		return c.key, nil
+	}
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		return "", err
+	}
+	c.checked = time.Now()
+	if c.key != "" && info.ModTime().Equal(c.modTime) {
+		return c.key, nil
+	}
+	b, err := os.ReadFile(c.Path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", errors.New("api key file " + c.Path + " is empty")
+	}
+	c.key, c.modTime = key, info.ModTime()
+	return c.key, nil
+}
+
+// Invalidate makes the next ApiKey call read the file again
+func (c *FileCredentials) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key = ""
+}
+
+// WithCredentials makes the session take the api key from p before every request instead of WaxpeerApiKey
+func WithCredentials(p CredentialProvider) Option {
+	return func(s *Session) {
+		s.credentials = p
+	}
+}
+
+// WithInvalidKeyHook calls fn when waxpeer rejects the api key
+func WithInvalidKeyHook(fn func(ctx context.Context, endpoint string)) Option {
+	return func(s *Session) {
+		s.invalidKeyHooks = append(s.invalidKeyHooks, fn)
+	}
+}
+
+// apiKey returns the key for the next request
+func (s *Session) apiKey(ctx context.Context) (string, error) {
+	if s.credentials == nil {
+		return s.WaxpeerApiKey, nil
+	}
+	return s.credentials.ApiKey(ctx)
+}
+
+// invalidKey reports whether waxpeer rejected the api key
+func invalidKey(resp *Response) bool {
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return true
+	}
+	success, msg := resp.Outcome()
+	if success {
+		return false
+	}
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "api") && strings.Contains(msg, "key") && !strings.Contains(msg, "steam")
+}
+
+// keyRejected runs the invalid key hooks and reports whether the provider has a new key to retry with
+func (s *Session) keyRejected(ctx context.Context, endpoint, key string) bool {
+	for _, hook := range s.invalidKeyHooks {
+		hook(ctx, endpoint)
+	}
+	invalidator, ok := s.credentials.(CredentialInvalidator)
+	if !ok {
+		return false
+	}
+	invalidator.Invalidate()
+	next, err := s.credentials.ApiKey(ctx)
+	return err == nil && next != key
+}
+
+type KeyPlacement int
+
+const (
+	KeyInQuery  KeyPlacement = iota // api query parameter, supported by every endpoint
+	KeyInHeader                     // "api" http header
+	KeyInBody                       // "api" field of json bodies
+)
+
+// keyHeader is the header of KeyInHeader, it has the name of the query parameter
+const keyHeader = "api"
+
+// WithKeyPlacement sends the api key in the "api" header or in the json body instead of the url
+// on the listed endpoints, ex: "edit-items", so it does not end up in proxy logs. The library does not know
+// which endpoints waxpeer accepts this on, list only endpoints you have verified. Other endpoints keep the key in the query
+func WithKeyPlacement(p KeyPlacement, endpoints ...string) Option {
+	return func(s *Session) {
+		s.keyPlacement = p
+		s.keyEndpoints = make(map[string]bool, len(endpoints))
+		for _, endpoint := range endpoints {
+			s.keyEndpoints[endpointName(endpoint)] = true
+		}
+	}
+}
+
+// placeKey puts the api key into the request according to the session key placement
+// if it is set for the endpoint, otherwise into the query
+func (s *Session) placeKey(req *Request, key string) {
+	placement := s.keyPlacement
+	if !s.keyEndpoints[req.Endpoint] {
+		placement = KeyInQuery
+	}
+	switch placement {
+	case KeyInHeader:
+		req.Params.Del("api")
+		req.Header[keyHeader] = key
+		return
+	case KeyInBody:
+		if body, ok := withBodyKey(req.Body, key); ok {
+			req.Params.Del("api")
+			req.Body = body
+			return
+		}
+	}
+	req.Params.Set("api", key)
+}
+
+func withBodyKey(body []byte, key string) ([]byte, bool) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, false
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return nil, false
+	}
+	fields["api"], _ = json.Marshal(key)
+	b, err := json.Marshal(fields)
+	return b, err == nil
+}
+
+// RequestError is returned when a request fails before waxpeer answers,
+// its message never contains the api key
+type RequestError struct {
+	Endpoint string
+	Err      error
+
+	msg string
+}
+
+func (e *RequestError) Error() string {
+	return e.msg
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func (s *Session) requestError(endpoint, key string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	msg := redactText(err.Error())
+	if key != "" {
+		msg = strings.ReplaceAll(msg, key, redacted)
+	}
+	return &RequestError{Endpoint: endpoint, Err: err, msg: endpoint + ": " + msg}
+}
@@ -0,0 +1,168 @@
+package waxpeer
+
+import "fmt"
+
+type Exterior string
+
+const (
+	ExteriorFN Exterior = "FN" // factory new
+	ExteriorMW Exterior = "MW" // minimal wear
+	ExteriorFT Exterior = "FT" // field-tested
+	ExteriorWW Exterior = "WW" // well-worn
+	ExteriorBS Exterior = "BS" // battle-scarred
+)
+
+// Brand is the item category
+type Brand string
+
+const (
+	BrandKnife      Brand = "knife"
+	BrandGloves     Brand = "gloves"
+	BrandRifle      Brand = "rifle"
+	BrandSniper     Brand = "sniper"
+	BrandPistol     Brand = "pistol"
+	BrandSMG        Brand = "smg"
+	BrandShotgun    Brand = "shotgun"
+	BrandMachinegun Brand = "machinegun"
+	BrandKey        Brand = "key"
+	BrandContainer  Brand = "container"
+	BrandSticker    Brand = "sticker"
+	BrandAgent      Brand = "agent"
+	BrandGraffiti   Brand = "graffiti"
+	BrandMusicKit   Brand = "music kit"
+	BrandPatch      Brand = "patch"
+	BrandPass       Brand = "pass"
+)
+
+type Order string
+
+const (
+	OrderAsc  Order = "asc"
+	OrderDesc Order = "desc"
+)
+
+type OrderBy string
+
+const (
+	OrderByPrice     OrderBy = "price"
+	OrderByProfit    OrderBy = "profit"
+	OrderByBestDeals OrderBy = "best_deals"
+)
+
+type Sort string
+
+const (
+	SortProfit    Sort = "profit"
+	SortAsc       Sort = "asc"
+	SortDesc      Sort = "desc"
+	SortBestDeals Sort = "best_deals"
+)
+
+var (
+	exteriors = map[Exterior]bool{ExteriorFN: true, ExteriorMW: true, ExteriorFT: true, ExteriorWW: true, ExteriorBS: true}
+	brands    = map[Brand]bool{
+		BrandKnife: true, BrandGloves: true, BrandRifle: true, BrandSniper: true, BrandPistol: true, BrandSMG: true,
+		BrandShotgun: true, BrandMachinegun: true, BrandKey: true, BrandContainer: true, BrandSticker: true,
+		BrandAgent: true, BrandGraffiti: true, BrandMusicKit: true, BrandPatch: true, BrandPass: true,
+	}
+	orders   = map[Order]bool{OrderAsc: true, OrderDesc: true}
+	orderBys = map[OrderBy]bool{OrderByPrice: true, OrderByProfit: true, OrderByBestDeals: true}
+	sorts    = map[Sort]bool{SortProfit: true, SortAsc: true, SortDesc: true, SortBestDeals: true}
+)
+
+// Validate rejects unknown exteriors, brands, sort orders and games, empty values are not filtered by
+func (c PricesFilterConfig) Validate() error {
+	switch {
+	case c.Exterior != "" && !exteriors[c.Exterior]:
+		return fmt.Errorf("unknown exterior %q", c.Exterior)
+	case c.Brand != "" && !brands[c.Brand]:
+		return fmt.Errorf("unknown brand %q", c.Brand)
+	case c.Order != "" && !orders[c.Order]:
+		return fmt.Errorf("unknown order %q", c.Order)
+	case c.OrderBy != "" && !orderBys[c.OrderBy]:
+		return fmt.Errorf("unknown order by %q", c.OrderBy)
+	case c.Sort != "" && !sorts[c.Sort]:
+		return fmt.Errorf("unknown sort %q", c.Sort)
+	case c.Game != "" && c.Game.AppID() == 0:
+		return fmt.Errorf("unknown game %q", c.Game)
+	case c.MaxPrice != 0 && c.MinPrice > c.MaxPrice:
+		return fmt.Errorf("min price %d is greater than max price %d", c.MinPrice, c.MaxPrice)
+	}
+	return nil
+}
+
+// FilterQuery composes a PricesFilterConfig, ex:
+// NewFilterQuery(GameCSGO).Brand(BrandRifle).Exterior(ExteriorFT).OrderBy(OrderByPrice, OrderAsc).Build()
+type FilterQuery struct {
+	c PricesFilterConfig
+}
+
+func NewFilterQuery(game Game) *FilterQuery {
+	return &FilterQuery{c: PricesFilterConfig{Game: game}}
+}
+
+func (q *FilterQuery) Search(name string) *FilterQuery {
+	q.c.Search = name
+	return q
+}
+
+func (q *FilterQuery) Brand(b Brand) *FilterQuery {
+	q.c.Brand = b
+	return q
+}
+
+func (q *FilterQuery) Exterior(e Exterior) *FilterQuery {
+	q.c.Exterior = e
+	return q
+}
+
+func (q *FilterQuery) OrderBy(by OrderBy, order Order) *FilterQuery {
+	q.c.OrderBy, q.c.Order = by, order
+	return q
+}
+
+func (q *FilterQuery) Sort(s Sort) *FilterQuery {
+	q.c.Sort = s
+	return q
+}
+
+// Price limits the price range, 1$ = 1000, 0 - no limit
+func (q *FilterQuery) Price(min, max uint64) *FilterQuery {
+	q.c.MinPrice, q.c.MaxPrice = min, max
+	return q
+}
+
+// Discount fetches items with discount of at least percent
+func (q *FilterQuery) Discount(percent uint64) *FilterQuery {
+	q.c.Discount = percent
+	return q
+}
+
+// By fetches items of the user with the UUID from their profile page
+func (q *FilterQuery) By(uuid string) *FilterQuery {
+	q.c.By = uuid
+	return q
+}
+
+// Page skips skip items and fetches at most limit
+func (q *FilterQuery) Page(skip, limit uint64) *FilterQuery {
+	q.c.Skip, q.c.Limit = skip, limit
+	return q
+}
+
+// Auto fetches only items instantly available for withdrawing
+func (q *FilterQuery) Auto() *FilterQuery {
+	q.c.Auto = true
+	return q
+}
+
+// Minified adds additional info like float to the items
+func (q *FilterQuery) Minified() *FilterQuery {
+	q.c.Minified = true
+	return q
+}
+
+// Build returns the validated config
+func (q *FilterQuery) Build() (PricesFilterConfig, error) {
+	return q.c, q.c.Validate()
+}
@@ -0,0 +1,258 @@
+package waxpeer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+type PriceSource int
+
+const (
+	PriceSourceSteam PriceSource = iota // average price from PricesSteam
+	PriceSourceMin                      // lowest market price from Prices
+	PriceSourceAvg                      // average market price from Prices
+	PriceSourceItem                     // steam_price.average reported by SellItems / SellOrders, listings only
+)
+
+// PriceGuard compares submitted prices against reference prices before buying or listing.
+// Attach it to a session: session.PriceGuard = &PriceGuard{MaxDeviation: 30}
+type PriceGuard struct {
+	Source       PriceSource
+	Game         Game          // game of the reference prices and inventory, default GameCSGO
+	MaxDeviation float64       // allowed deviation from the reference price in percent, ex: 30
+	WarnOnly     bool          // if true, deviations are only reported to OnDeviation and never rejected
+	Strict       bool          // if true, items without a reference price are rejected
+	MaxAge       time.Duration // reference prices older than this are reloaded, 0 - load once
+	OnDeviation  func(d *PriceDeviation)
+
+	mu              sync.Mutex
+	loaded          time.Time
+	refs            map[string]int64
+	overrides       map[string]int64 // set by SetReference, kept across refreshes
+	items           map[ItemID]*guardItem
+	inventoryLoaded bool
+	listedLoaded    bool
+}
+
+type guardItem struct {
+	name  string
+	steam int64
+}
+
+// PriceDeviation is returned as an error when a price is rejected by the PriceGuard
+type PriceDeviation struct {
+	ItemID    ItemID
+	Name      string
+	Price     int64
+	Reference int64   // 0 if no reference price was found
+	Deviation float64 // in percent, negative if the price is below the reference
+}
+
+func (d *PriceDeviation) Error() string {
+	if d.Reference == 0 {
+		return fmt.Sprintf("no reference price for %q (item %d)", d.Name, d.ItemID)
+	}
+	return fmt.Sprintf("price %d for %q (item %d) deviates %.1f%% from reference %d", d.Price, d.Name, d.ItemID, d.Deviation, d.Reference)
+}
+
+// Refresh reloads the reference prices
+func (g *PriceGuard) Refresh(s *Session) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.refresh(s)
+}
+
+// SetReference overrides the reference price of an item by name, the override survives refreshes
+func (g *PriceGuard) SetReference(name string, price int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.overrides == nil {
+		g.overrides = make(map[string]int64)
+	}
+	g.overrides[name] = price
+}
+
+func (g *PriceGuard) refresh(s *Session) error {
+	refs := make(map[string]int64)
+	switch g.Source {
+	case PriceSourceSteam:
+		items, err := s.PricesSteam(g.game())
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			refs[item.Name] = item.Average
+		}
+	case PriceSourceMin, PriceSourceAvg:
+		items, err := s.Prices(PricesConfig{Game: g.game()})
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if g.Source == PriceSourceMin {
+				refs[item.Name] = item.Min
+			} else {
+				refs[item.Name] = item.Avg
+			}
+		}
+	}
+	g.refs = refs
+	g.items = make(map[ItemID]*guardItem)
+	g.inventoryLoaded, g.listedLoaded = false, false
+	g.loaded = time.Now()
+	return nil
+}
+
+func (g *PriceGuard) expired() bool {
+	if g.loaded.IsZero() {
+		return true
+	}
+	return g.MaxAge != 0 && time.Since(g.loaded) > g.MaxAge
+}
+
+// loadItems fetches names and steam prices of listed items (SellOrders) or inventory items (SellItems)
+func (g *PriceGuard) loadItems(s *Session, listed bool) error {
+	if listed && g.listedLoaded || !listed && g.inventoryLoaded {
+		return nil
+	}
+	if listed {
+		orders, err := s.SellOrders()
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			g.items[o.ItemID] = &guardItem{name: o.Name, steam: int64(o.SteamPrice.Average)}
+		}
+	} else {
+		for skip := uint64(0); ; {
+			items, err := s.SellItems(SellItemsConfig{Skip: skip, Game: g.game()})
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				g.items[item.ItemID] = &guardItem{name: item.Name, steam: item.SteamPrice.Average}
+			}
+			if len(items) == 0 {
+				break
+			}
+			skip += uint64(len(items))
+		}
+	}
+	if listed {
+		g.listedLoaded = true
+	} else {
+		g.inventoryLoaded = true
+	}
+	return nil
+}
+
+func (g *PriceGuard) game() Game {
+	if g.Game != "" {
+		return g.Game
+	}
+	return GameCSGO
+}
+
+func (g *PriceGuard) reference(name string, item *guardItem) int64 {
+	if ref, ok := g.overrides[name]; ok {
+		return ref
+	}
+	if g.Source == PriceSourceItem {
+		if item == nil {
+			return 0
+		}
+		return item.steam
+	}
+	return g.refs[name]
+}
+
+func (g *PriceGuard) check(itemID ItemID, name string, price int64, ref int64) error {
+	d := &PriceDeviation{ItemID: itemID, Name: name, Price: price, Reference: ref}
+	if ref <= 0 {
+		if !g.Strict {
+			return nil
+		}
+	} else {
+		d.Deviation = float64(price-ref) / float64(ref) * 100
+		if math.Abs(d.Deviation) <= g.MaxDeviation {
+			return nil
+		}
+	}
+	if g.OnDeviation != nil {
+		g.OnDeviation(d)
+	}
+	if g.WarnOnly {
+		return nil
+	}
+	return d
+}
+
+// checkSell verifies prices of items for Sell (listed = false) or SellEdit (listed = true)
+func (g *PriceGuard) checkSell(s *Session, c []SellItemConfig, listed bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.expired() {
+		if err := g.refresh(s); err != nil {
+			return err
+		}
+	}
+	if err := g.loadItems(s, listed); err != nil {
+		return err
+	}
+	// items listed or acquired after the snapshot was loaded are missing, reload it once
+	for _, i := range c {
+		if g.items[i.ItemID] == nil {
+			if listed {
+				g.listedLoaded = false
+			} else {
+				g.inventoryLoaded = false
+			}
+			if err := g.loadItems(s, listed); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for _, i := range c {
+		item := g.items[i.ItemID]
+		var name string
+		if item != nil {
+			name = item.name
+		}
+		if err := g.check(i.ItemID, name, i.Price, g.reference(name, item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBuy verifies the price of an item by name, itemID is 0 when buying by name
+func (g *PriceGuard) checkBuy(s *Session, itemID ItemID, name string, price int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.expired() {
+		if err := g.refresh(s); err != nil {
+			return err
+		}
+	}
+	var item *guardItem
+	if itemID != 0 {
+		item = g.items[itemID]
+		if item == nil {
+			items, err := s.ItemAvailable(&[]ItemID{itemID})
+			if err != nil {
+				return err
+			}
+			if len(items) != 0 {
+				item = &guardItem{name: items[0].Name}
+				g.items[itemID] = item
+			}
+		}
+		if item != nil {
+			name = item.name
+		}
+	}
+	return g.check(itemID, name, price, g.reference(name, item))
+}
@@ -0,0 +1,167 @@
+package waxpeer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// trade status of a cancelled trade in AccountHistory
+const tradeStatusCancelled = 6
+
+const bucketCheckpoints = "checkpoints"
+
+type PendingTrade struct {
+	Status  int64     `json:"status"`
+	Created time.Time `json:"created"`
+}
+
+// HistoryCheckpoint is the state of HistorySyncer for one account
+type HistoryCheckpoint struct {
+	NewestID   int64                  `json:"newest_id"`
+	NewestTime time.Time              `json:"newest_time"`
+	Pending    map[int64]PendingTrade `json:"pending"` // trades that were not finished yet, by id
+}
+
+// CheckpointStore saves HistorySyncer checkpoints
+type CheckpointStore interface {
+	LoadCheckpoint(account string) (*HistoryCheckpoint, error) // returns nil, nil if there is no checkpoint
+	SaveCheckpoint(account string, c *HistoryCheckpoint) error
+}
+
+// StoreCheckpoints keeps checkpoints in a Store
+type StoreCheckpoints struct {
+	Store Store
+}
+
+func (s StoreCheckpoints) LoadCheckpoint(account string) (*HistoryCheckpoint, error) {
+	b, err := s.Store.Get(bucketCheckpoints, account)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	var c HistoryCheckpoint
+	if err = json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s StoreCheckpoints) SaveCheckpoint(account string, c *HistoryCheckpoint) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return s.Store.Put(bucketCheckpoints, account, b)
+}
+
+type HistorySync struct {
+	New     []*accountHistory // trades created after the checkpoint
+	Updated []*accountHistory // unfinished trades whose status has changed
+}
+
+// HistorySyncer returns only new and updated trades from AccountHistory,
+// it pages until it reaches the checkpoint and all unfinished trades
+type HistorySyncer struct {
+	Session     *Session
+	Checkpoints CheckpointStore
+	Account     string // checkpoint key, by default the waxpeer user id
+	MaxPages    int    // 0 - no limit. The first Sync stops after this many pages, later ones fail if the checkpoint is not reached
+}
+
+func tradeFinished(status int64) bool {
+	return status == tradeStatusDone || status == tradeStatusCancelled
+}
+
+// Sync fetches new pages of AccountHistory and saves the new checkpoint
+func (h *HistorySyncer) Sync() (*HistorySync, error) {
+	account, err := h.account()
+	if err != nil {
+		return nil, err
+	}
+	cp, err := h.Checkpoints.LoadCheckpoint(account)
+	if err != nil {
+		return nil, err
+	}
+	first := cp == nil
+	if first {
+		cp = &HistoryCheckpoint{}
+	}
+	next := &HistoryCheckpoint{NewestID: cp.NewestID, NewestTime: cp.NewestTime, Pending: make(map[int64]PendingTrade)}
+	for id, p := range cp.Pending {
+		next.Pending[id] = p
+	}
+	unseen := make(map[int64]bool, len(cp.Pending))
+	for id := range cp.Pending {
+		unseen[id] = true
+	}
+
+	result := &HistorySync{}
+	reached := first
+	for skip, page := uint64(0), 0; h.MaxPages == 0 || page < h.MaxPages; page++ {
+		history, err := h.Session.AccountHistory(AccountHistoryConfig{Skip: skip})
+		if err != nil {
+			return nil, err
+		}
+		if len(history) == 0 {
+			reached = true
+			break
+		}
+		for _, t := range history {
+			if t.ID > cp.NewestID {
+				result.New = append(result.New, t)
+			} else if p, ok := cp.Pending[t.ID]; ok {
+				delete(unseen, t.ID)
+				if p.Status != t.Status {
+					result.Updated = append(result.Updated, t)
+				}
+			}
+			if t.ID > next.NewestID {
+				next.NewestID, next.NewestTime = t.ID, t.Created
+			}
+			if tradeFinished(t.Status) {
+				delete(next.Pending, t.ID)
+			} else {
+				next.Pending[t.ID] = PendingTrade{Status: t.Status, Created: t.Created}
+			}
+		}
+		last := history[len(history)-1]
+		if last.ID <= cp.NewestID {
+			reached = true // unfinished trades not seen yet keep their old status in the checkpoint
+			if !first && reachedPending(last.Created, cp.Pending, unseen) {
+				break
+			}
+		}
+		skip += uint64(len(history))
+	}
+	// saving the checkpoint now would skip the trades on the pages that were not fetched
+	if !reached {
+		return nil, fmt.Errorf("history sync stopped after %d pages before reaching the checkpoint, increase MaxPages", h.MaxPages)
+	}
+	if err = h.Checkpoints.SaveCheckpoint(account, next); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// reachedPending reports whether all unseen pending trades are newer than t,
+// so they are not going to appear on the next pages
+func reachedPending(t time.Time, pending map[int64]PendingTrade, unseen map[int64]bool) bool {
+	for id := range unseen {
+		if !pending[id].Created.After(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *HistorySyncer) account() (string, error) {
+	if h.Account != "" {
+		return h.Account, nil
+	}
+	user, err := h.Session.AccountInformation()
+	if err != nil {
+		return "", err
+	}
+	h.Account = user.UserID
+	return h.Account, nil
+}
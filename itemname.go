@@ -0,0 +1,151 @@
+package waxpeer
+
+import "strings"
+
+const (
+	star     = "★"
+	statTrak = "StatTrak™"
+	souvenir = "Souvenir"
+)
+
+var exteriorNames = map[Exterior]string{
+	ExteriorFN: "Factory New",
+	ExteriorMW: "Minimal Wear",
+	ExteriorFT: "Field-Tested",
+	ExteriorWW: "Well-Worn",
+	ExteriorBS: "Battle-Scarred",
+}
+
+// doppler phases, ex: ★ Karambit | Doppler Phase 2 (Factory New)
+var phases = []string{"Phase 1", "Phase 2", "Phase 3", "Phase 4", "Ruby", "Sapphire", "Black Pearl", "Emerald"}
+
+// unicode and ascii variants replaced before parsing
+var nameReplacer = strings.NewReplacer(
+	"\u00a0", " ",
+	"\u3000", " ",
+	"\u2606", star,
+	"\uff5c", "|",
+	"(TM)", "\u2122",
+	"(tm)", "\u2122",
+	"\u2010", "-",
+	"\u2011", "-",
+)
+
+// ItemName is a decomposed market hash name, ex: ★ StatTrak™ Karambit | Doppler Phase 2 (Factory New)
+type ItemName struct {
+	Star     bool // ★, knives and gloves
+	StatTrak bool
+	Souvenir bool
+	Weapon   string   // ex: AK-47, Karambit, Sticker, or the whole name of items without a skin
+	Skin     string   // ex: Redline, empty for vanilla knives
+	Phase    string   // doppler phase, ex: Phase 2, Ruby
+	Exterior Exterior // empty for items without an exterior
+}
+
+// ParseItemName decomposes a market hash name, unicode variants of ★, ™ and spaces are normalized
+func ParseItemName(name string) ItemName {
+	var n ItemName
+	name = strings.Join(strings.Fields(nameReplacer.Replace(name)), " ")
+	if rest, ok := cutPrefixFold(name, star); ok {
+		n.Star, name = true, rest
+	}
+	if rest, ok := cutPrefixFold(name, statTrak); ok {
+		n.StatTrak, name = true, rest
+	} else if rest, ok = cutPrefixFold(name, "StatTrak"); ok {
+		n.StatTrak, name = true, rest
+	}
+	if rest, ok := cutPrefixFold(name, souvenir+" "); ok && strings.Contains(rest, " | ") {
+		n.Souvenir, name = true, rest
+	}
+	n.Phase, name = cutPhase(name, " - ")
+	for e, long := range exteriorNames {
+		if strings.HasSuffix(name, " ("+long+")") {
+			n.Exterior, name = e, strings.TrimSuffix(name, " ("+long+")")
+			break
+		}
+	}
+	if n.Phase == "" {
+		n.Phase, name = cutPhase(name, " - ")
+	}
+	n.Weapon = name
+	if i := strings.Index(name, " | "); i != -1 {
+		n.Weapon, n.Skin = name[:i], name[i+3:]
+	}
+	if n.Phase == "" && strings.Contains(n.Skin, "Doppler") {
+		n.Phase, n.Skin = cutPhase(n.Skin, " ")
+	}
+	return n
+}
+
+// NormalizeItemName returns the name in the form used by waxpeer
+func NormalizeItemName(name string) string {
+	return ParseItemName(name).String()
+}
+
+// String recomposes the market hash name
+func (n ItemName) String() string {
+	var b strings.Builder
+	if n.Star {
+		b.WriteString(star + " ")
+	}
+	if n.StatTrak {
+		b.WriteString(statTrak + " ")
+	}
+	if n.Souvenir {
+		b.WriteString(souvenir + " ")
+	}
+	b.WriteString(n.Weapon)
+	if n.Skin != "" {
+		b.WriteString(" | " + n.Skin)
+	}
+	if n.Phase != "" {
+		b.WriteString(" " + n.Phase)
+	}
+	if long, ok := exteriorNames[n.Exterior]; ok {
+		b.WriteString(" (" + long + ")")
+	}
+	return b.String()
+}
+
+// Family returns the name without the exterior, items with the same family differ only by wear
+func (n ItemName) Family() string {
+	n.Exterior = ""
+	return n.String()
+}
+
+// GroupPrices groups Prices results by family, ex: all exteriors of one skin
+func GroupPrices(items []*Price) map[string][]*Price {
+	groups := make(map[string][]*Price)
+	for _, item := range items {
+		family := ParseItemName(item.Name).Family()
+		groups[family] = append(groups[family], item)
+	}
+	return groups
+}
+
+// GroupPricesFilter groups PricesFilter results by family
+func GroupPricesFilter(items []*PriceFilter) map[string][]*PriceFilter {
+	groups := make(map[string][]*PriceFilter)
+	for _, item := range items {
+		family := ParseItemName(item.Name).Family()
+		groups[family] = append(groups[family], item)
+	}
+	return groups
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return strings.TrimSpace(s[len(prefix):]), true
+}
+
+// cutPhase removes a doppler phase preceded by sep from the end of s
+func cutPhase(s, sep string) (string, string) {
+	for _, phase := range phases {
+		if strings.HasSuffix(s, sep+phase) {
+			return phase, strings.TrimSuffix(s, sep+phase)
+		}
+	}
+	return "", s
+}
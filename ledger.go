@@ -0,0 +1,222 @@
+package waxpeer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// trade status of a successfully finished trade in AccountHistory
+const tradeStatusDone = 5
+
+type LedgerKind int
+
+const (
+	LedgerBuy LedgerKind = iota
+	LedgerSell
+)
+
+type LedgerEntry struct {
+	Kind   LedgerKind
+	ID     string // id of the trade or buy order, used to skip duplicates
+	Source string // history, order-history, history-id, p2p or manual
+	Name   string
+	Price  int64 // 1$ = 1000
+	Time   time.Time
+}
+
+// PnLMatch is a sell matched with the oldest unmatched buy of the same item
+type PnLMatch struct {
+	Name   string
+	Buy    *LedgerEntry // nil if the item was sold without a known buy
+	Sell   *LedgerEntry
+	Fee    int64
+	Profit int64 // sell price - fee - buy price
+}
+
+type PnLPosition struct {
+	Name       string
+	Quantity   int
+	Cost       int64
+	Value      int64 // current price of the items minus fees, 0 if the price is unknown
+	Unrealized int64
+}
+
+type PnLReport struct {
+	From       time.Time
+	To         time.Time
+	Realized   int64
+	Unrealized int64
+	Fees       int64
+	Matches    []*PnLMatch
+	Open       []*PnLPosition
+}
+
+// Ledger collects buys and sells and computes profit and loss, buys are matched to sells by item name (FIFO).
+// Entries are deduplicated by feed and id only, a purchase ingested from two feeds is counted twice
+type Ledger struct {
+	Fee float64 // sell fee as a fraction, ex: 0.05, see LoadFees
+
+	mu      sync.Mutex
+	entries map[string]*LedgerEntry
+}
+
+// LoadFees sets Fee from the account sell fees, waxpeer reports sell_fees as a fraction of the sale price, ex: 0.05
+func (l *Ledger) LoadFees(s *Session) error {
+	user, err := s.AccountInformation()
+	if err != nil {
+		return err
+	}
+	fee := user.SellFees
+	if fee < 0 || fee >= 1 {
+		return fmt.Errorf("unexpected sell_fees %v, expected a fraction of the sale price", fee)
+	}
+	l.mu.Lock()
+	l.Fee = fee
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *Ledger) add(e *LedgerEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.entries == nil {
+		l.entries = make(map[string]*LedgerEntry)
+	}
+	key := strconv.Itoa(int(e.Kind)) + "/" + e.Source + "/" + e.ID
+	l.entries[key] = e
+}
+
+// AddBuy records a purchase made outside of the supported history endpoints
+func (l *Ledger) AddBuy(id, name string, price int64, t time.Time) {
+	l.add(&LedgerEntry{Kind: LedgerBuy, ID: id, Source: "manual", Name: name, Price: price, Time: t})
+}
+
+// AddSale records a sale, ex: from your own bookkeeping
+func (l *Ledger) AddSale(id, name string, price int64, t time.Time) {
+	l.add(&LedgerEntry{Kind: LedgerSell, ID: id, Source: "manual", Name: name, Price: price, Time: t})
+}
+
+// IngestAccountHistory records finished purchases from AccountHistory,
+// do not ingest the same purchases from AccountHistoryID as well
+func (l *Ledger) IngestAccountHistory(history []*accountHistory) {
+	for _, h := range history {
+		if h.Status != tradeStatusDone {
+			continue
+		}
+		l.add(&LedgerEntry{Kind: LedgerBuy, ID: strconv.FormatInt(h.ID, 10), Source: "history", Name: h.Name, Price: h.Price, Time: h.Created})
+	}
+}
+
+// IngestOrderHistory records items bought by buy orders from OrderHistory
+func (l *Ledger) IngestOrderHistory(history []*orderHistory) {
+	for _, h := range history {
+		price, err := strconv.ParseInt(h.Price, 10, 64)
+		if err != nil {
+			continue
+		}
+		l.add(&LedgerEntry{Kind: LedgerBuy, ID: strconv.FormatInt(h.ID, 10), Source: "order-history", Name: h.ItemName, Price: price, Time: h.Created})
+	}
+}
+
+// IngestAccountHistoryID records finished purchases from AccountHistoryID,
+// do not ingest the same purchases from AccountHistory as well
+func (l *Ledger) IngestAccountHistoryID(trades []*accountHistoryID) {
+	for _, t := range trades {
+		if !t.Done {
+			continue
+		}
+		l.add(&LedgerEntry{Kind: LedgerBuy, ID: strconv.FormatUint(t.ID, 10), Source: "history-id", Name: t.Name, Price: int64(t.Price), Time: time.Unix(int64(t.LastUpdated), 0)})
+	}
+}
+
+// IngestP2PTrades records sold items from finished AccountReadyToTransferP2P trades
+func (l *Ledger) IngestP2PTrades(trades []*tradesReadyToTransferP2P) {
+	for _, t := range trades {
+		if !t.Done {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, t.Created)
+		for _, item := range t.Items {
+			l.add(&LedgerEntry{Kind: LedgerSell, ID: strconv.FormatInt(item.ID, 10), Source: "p2p", Name: item.Name, Price: item.Price, Time: created})
+		}
+	}
+}
+
+// Entries returns all recorded entries sorted by time
+func (l *Ledger) Entries() []*LedgerEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]*LedgerEntry, 0, len(l.entries))
+	for _, e := range l.entries {
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries
+}
+
+// Report computes realized profit for sells between from and to (zero time - no bound)
+// and unrealized profit of items still held, valued at the current min prices
+func (l *Ledger) Report(from, to time.Time, prices []*Price) *PnLReport {
+	l.mu.Lock()
+	fee := l.Fee
+	l.mu.Unlock()
+
+	report := &PnLReport{From: from, To: to}
+	held := make(map[string][]*LedgerEntry)
+	for _, e := range l.Entries() {
+		if !to.IsZero() && !e.Time.Before(to) {
+			break
+		}
+		if e.Kind == LedgerBuy {
+			held[e.Name] = append(held[e.Name], e)
+			continue
+		}
+		m := &PnLMatch{Name: e.Name, Sell: e, Fee: int64(float64(e.Price) * fee)}
+		if q := held[e.Name]; len(q) != 0 {
+			m.Buy, held[e.Name] = q[0], q[1:]
+		}
+		if !from.IsZero() && e.Time.Before(from) {
+			continue
+		}
+		m.Profit = e.Price - m.Fee
+		if m.Buy != nil {
+			m.Profit -= m.Buy.Price
+		}
+		report.Matches = append(report.Matches, m)
+		report.Realized += m.Profit
+		report.Fees += m.Fee
+	}
+
+	current := make(map[string]int64, len(prices))
+	for _, p := range prices {
+		current[p.Name] = p.Min
+	}
+	names := make([]string, 0, len(held))
+	for name, q := range held {
+		if len(q) != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pos := &PnLPosition{Name: name, Quantity: len(held[name])}
+		for _, e := range held[name] {
+			pos.Cost += e.Price
+		}
+		if p, ok := current[name]; ok {
+			pos.Value = int64(float64(p)*(1-fee)) * int64(pos.Quantity)
+			pos.Unrealized = pos.Value - pos.Cost
+			report.Unrealized += pos.Unrealized
+		}
+		report.Open = append(report.Open, pos)
+	}
+	return report
+}
@@ -0,0 +1,141 @@
+package waxpeer
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// query and body parameters that are never logged
+var secretParams = map[string]bool{
+	"api":       true,
+	"steam_api": true,
+	"token":     true,
+	"tradelink": true,
+	"link":      true,
+}
+
+var (
+	secretQuery = regexp.MustCompile(`\b(api|steam_api|token|tradelink|link)=[^&\s"]*`)
+	secretJson  = regexp.MustCompile(`"(api|steam_api|token|tradelink|link)"\s*:\s*"[^"]*"`)
+	tradeToken  = regexp.MustCompile(`([?&;]token=)[\w-]+`)
+)
+
+type LogConfig struct {
+	Logger        *slog.Logger
+	RequestLevel  slog.Level // level of outgoing requests
+	ResponseLevel slog.Level // level of successful responses
+	ErrorLevel    slog.Level // level of failed requests
+	Bodies        bool       // log request and response bodies, secrets are redacted
+	MaxBody       int        // truncate logged bodies, 0 - 1024 bytes
+}
+
+// WithLogger logs requests and responses at debug level and failures at warn level,
+// api keys, steam api keys, trade tokens and tradelinks are redacted
+func WithLogger(l *slog.Logger) Option {
+	return WithLogConfig(LogConfig{
+		Logger:        l,
+		RequestLevel:  slog.LevelDebug,
+		ResponseLevel: slog.LevelDebug,
+		ErrorLevel:    slog.LevelWarn,
+	})
+}
+
+// WithLogConfig is WithLogger with configurable levels
+func WithLogConfig(c LogConfig) Option {
+	return func(s *Session) {
+		if c.MaxBody == 0 {
+			c.MaxBody = 1024
+		}
+		s.log = &c
+	}
+}
+
+// redactParams returns a copy of params with secret values replaced
+func redactParams(params url.Values) string {
+	safe := make(url.Values, len(params))
+	for k, v := range params {
+		if secretParams[k] {
+			safe[k] = []string{redacted}
+			continue
+		}
+		values := make([]string, len(v))
+		for i := range v {
+			values[i] = redactText(v[i])
+		}
+		safe[k] = values
+	}
+	return strings.ReplaceAll(safe.Encode(), url.QueryEscape(redacted), redacted)
+}
+
+// redactText replaces secrets in urls, query strings and json
+func redactText(text string) string {
+	text = secretQuery.ReplaceAllString(text, "$1="+redacted)
+	text = secretJson.ReplaceAllString(text, `"$1":"`+redacted+`"`)
+	return tradeToken.ReplaceAllString(text, "${1}"+redacted)
+}
+
+// body redacts secrets and the api key of the request before truncating, so a secret cut by MaxBody is not logged
+func (c *LogConfig) body(key string, b []byte) string {
+	text := redactText(string(b))
+	if key != "" {
+		text = strings.ReplaceAll(text, key, redacted)
+	}
+	if len(text) > c.MaxBody {
+		text = text[:c.MaxBody] + "..."
+	}
+	return text
+}
+
+func (c *LogConfig) request(ctx context.Context, key string, method, endpoint string, params url.Values, body []byte) {
+	if !c.Logger.Enabled(ctx, c.RequestLevel) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("endpoint", endpoint),
+		slog.String("params", redactParams(params)),
+	}
+	if c.Bodies && len(body) != 0 {
+		attrs = append(attrs, slog.String("body", c.body(key, body)))
+	}
+	c.Logger.LogAttrs(ctx, c.RequestLevel, "waxpeer request", attrs...)
+}
+
+func (c *LogConfig) response(ctx context.Context, key string, endpoint string, resp *Response, kind string, err error, attempts int, d time.Duration) {
+	level := c.ResponseLevel
+	if kind != "" {
+		level = c.ErrorLevel
+	}
+	if !c.Logger.Enabled(ctx, level) {
+		return
+	}
+	var status int
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	attrs := []slog.Attr{
+		slog.String("endpoint", endpoint),
+		slog.Int("status", status),
+		slog.Int("attempts", attempts),
+		slog.Duration("duration", d),
+	}
+	if kind != "" {
+		attrs = append(attrs, slog.String("error_kind", kind))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", c.body(key, []byte(err.Error()))))
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("size", len(resp.Body)))
+		if c.Bodies {
+			attrs = append(attrs, slog.String("body", c.body(key, resp.Body)))
+		}
+	}
+	c.Logger.LogAttrs(ctx, level, "waxpeer response", attrs...)
+}
@@ -0,0 +1,85 @@
+// Package metrics provides prometheus collectors for waxpeer sessions.
+//
+//	collector := metrics.NewCollector("waxpeer")
+//	registry.MustRegister(collector)
+//	session := waxpeer.CreateSession(key, waxpeer.WithMetrics(collector))
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements waxpeer.Metrics and prometheus.Collector
+type Collector struct {
+	requests      *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	rateLimitWait *prometheus.HistogramVec
+	retries       *prometheus.CounterVec
+}
+
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of requests to the waxpeer api by endpoint.",
+		}, []string{"endpoint"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_errors_total",
+			Help:      "Number of failed requests to the waxpeer api by endpoint and kind: transport, status or api.",
+		}, []string{"endpoint", "kind"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests to the waxpeer api by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		rateLimitWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_wait_seconds",
+			Help:      "Time requests spent waiting for the rate limiter by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Number of retried requests to the waxpeer api by endpoint.",
+		}, []string{"endpoint"}),
+	}
+}
+
+func (c *Collector) ObserveRequest(endpoint string, duration time.Duration, errorKind string) {
+	c.requests.WithLabelValues(endpoint).Inc()
+	c.latency.WithLabelValues(endpoint).Observe(duration.Seconds())
+	if errorKind != "" {
+		c.errors.WithLabelValues(endpoint, errorKind).Inc()
+	}
+}
+
+func (c *Collector) ObserveRateLimitWait(endpoint string, wait time.Duration) {
+	c.rateLimitWait.WithLabelValues(endpoint).Observe(wait.Seconds())
+}
+
+func (c *Collector) ObserveRetry(endpoint string) {
+	c.retries.WithLabelValues(endpoint).Inc()
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.latency.Describe(ch)
+	c.rateLimitWait.Describe(ch)
+	c.retries.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.latency.Collect(ch)
+	c.rateLimitWait.Collect(ch)
+	c.retries.Collect(ch)
+}
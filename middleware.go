@@ -0,0 +1,83 @@
+package waxpeer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"sync"
+)
+
+// Request is a call to a waxpeer endpoint passed through the middleware chain
+type Request struct {
+	Method   string            // GET or POST
+	Endpoint string            // ex: get-items-list
+	Params   url.Values        // query parameters, including the api key
+	Body     []byte            // json body of POST requests
+	Header   map[string]string // additional http headers
+
+	key    string                            // api key placed by the session, redacted from logs
+	stream func(r io.Reader, resp *Response) // set by the Each methods, reads the body of 2xx responses instead of Response.Body
+}
+
+func (r *Request) url() string {
+	return defaultURL + r.Endpoint + "?" + r.Params.Encode()
+}
+
+type Response struct {
+	StatusCode int
+	Body       []byte
+
+	once       sync.Once
+	hasSuccess bool
+	success    bool
+	msg        string
+}
+
+// Outcome decodes the success and msg fields of the response body
+func (r *Response) Outcome() (success bool, msg string) {
+	r.decode()
+	return r.success, r.msg
+}
+
+// failed reports whether the body has success: false, bodies without the field are not failures
+func (r *Response) failed() bool {
+	r.decode()
+	return r.hasSuccess && !r.success
+}
+
+// setOutcome records success and msg of a body that is not kept in Body
+func (r *Response) setOutcome(success bool, msg string) {
+	r.once.Do(func() {
+		r.hasSuccess, r.success, r.msg = true, success, msg
+	})
+}
+
+func (r *Response) decode() {
+	r.once.Do(func() {
+		var body struct {
+			Success *bool  `json:"success"`
+			Msg     string `json:"msg"`
+		}
+		if json.Unmarshal(r.Body, &body) != nil {
+			return
+		}
+		r.msg = body.Msg
+		if body.Success != nil {
+			r.hasSuccess, r.success = true, *body.Success
+		}
+	})
+}
+
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a Handler, it may change the request, return its own response or error
+// without calling next, or inspect the response returned by next
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middlewares to the session, the first one is the outermost
+func WithMiddleware(m ...Middleware) Option {
+	return func(s *Session) {
+		s.middleware = append(s.middleware, m...)
+	}
+}
@@ -0,0 +1,47 @@
+package waxpeer
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Option func(s *Session)
+
+// WithMetrics reports every request to m, see the metrics package for prometheus collectors
+func WithMetrics(m Metrics) Option {
+	return func(s *Session) {
+		s.metrics = m
+	}
+}
+
+// WithRateLimit spaces requests of the session at least interval apart
+func WithRateLimit(interval time.Duration) Option {
+	return func(s *Session) {
+		s.limiter = &rateLimiter{interval: interval}
+	}
+}
+
+// WithRetries retries requests answered with 429, read-only requests are also retried
+// after transport errors and 5xx responses. The delay grows by backoff after every attempt
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(s *Session) {
+		s.retries = retries
+		s.retryBackoff = backoff
+	}
+}
+
+// WithTracerProvider creates a span for every call and every http request of the session,
+// use Session.WithContext to make them children of the caller's span
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Session) {
+		s.tracer = tp.Tracer("github.com/1makarov/go-waxpeer")
+	}
+}
+
+// WithoutCompression asks waxpeer for uncompressed responses, by default gzip and deflate are accepted
+func WithoutCompression() Option {
+	return func(s *Session) {
+		s.noCompression = true
+	}
+}
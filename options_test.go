@@ -0,0 +1,29 @@
+package waxpeer
+
+import "testing"
+
+func TestWithoutCompression(t *testing.T) {
+	body := pricesBody(t, 20000)
+	read := fakeWaxpeer(t, body, true)
+
+	wire := func(s *Session) int64 {
+		before := read()
+		prices, err := s.Prices(PricesConfig{Game: GameCSGO})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(prices) != 20000 {
+			t.Fatalf("got %d items, want 20000", len(prices))
+		}
+		return read() - before
+	}
+	gzip := wire(CreateSession("key"))
+	identity := wire(CreateSession("key", WithoutCompression()))
+	t.Logf("%d byte body: %d bytes on the wire with gzip, %d without compression", len(body), gzip, identity)
+	if identity < int64(len(body)) {
+		t.Fatalf("read %d bytes without compression, body is %d", identity, len(body))
+	}
+	if gzip*5 > identity {
+		t.Fatalf("read %d bytes with gzip, want less than a fifth of %d", gzip, identity)
+	}
+}
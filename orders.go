@@ -0,0 +1,240 @@
+package waxpeer
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type DesiredOrder struct {
+	Name   string // name of item
+	Price  uint64 // max price | 1$ = 1000
+	Amount uint64 // amount of items
+}
+
+type OrderAction int
+
+const (
+	OrderActionCreate OrderAction = iota
+	OrderActionEdit
+	OrderActionRemove
+)
+
+func (a OrderAction) String() string {
+	switch a {
+	case OrderActionCreate:
+		return "create"
+	case OrderActionEdit:
+		return "edit"
+	case OrderActionRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+type OrderStep struct {
+	Action    OrderAction
+	ID        int64 // buy order id, 0 for create
+	Name      string
+	Price     uint64 // new price
+	Amount    uint64 // new amount
+	OldPrice  uint64
+	OldAmount uint64
+	Reason    string
+	Filled    int64 // items bought at once when the order was created, set by Apply
+	Err       error // set by Apply if the step failed
+	Done      bool  // set by Apply if the step was applied
+}
+
+// OrderPlan is the list of steps needed to turn open buy orders into the desired ones
+type OrderPlan struct {
+	Steps     []*OrderStep
+	Unchanged int // number of open orders that already match
+}
+
+// BuyOrderManager reconciles the desired set of buy orders with the open ones
+type BuyOrderManager struct {
+	Session       *Session
+	KeepUnmanaged bool // do not remove open orders for names missing from the desired set
+}
+
+// Plan fetches all open buy orders and returns the steps needed to reach the desired set,
+// nothing is changed on waxpeer
+func (m *BuyOrderManager) Plan(desired []DesiredOrder) (*OrderPlan, error) {
+	open, err := m.open()
+	if err != nil {
+		return nil, err
+	}
+	plan := &OrderPlan{}
+	byName := make(map[string][]*orderOpen)
+	for _, o := range open {
+		byName[o.Name] = append(byName[o.Name], o)
+	}
+	wanted := make(map[string]bool)
+	for _, d := range desired {
+		if wanted[d.Name] {
+			return nil, fmt.Errorf("duplicate desired order %q", d.Name)
+		}
+		wanted[d.Name] = true
+		orders := byName[d.Name]
+		if len(orders) == 0 {
+			if d.Amount != 0 {
+				plan.Steps = append(plan.Steps, &OrderStep{
+					Action: OrderActionCreate,
+					Name:   d.Name,
+					Price:  d.Price,
+					Amount: d.Amount,
+					Reason: "missing",
+				})
+			}
+			continue
+		}
+		o := orders[0]
+		price, err := strconv.ParseUint(o.Price, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case d.Amount == 0:
+			plan.Steps = append(plan.Steps, removeStep(o, price, "desired amount is 0"))
+		case price != d.Price || uint64(o.Amount) != d.Amount:
+			plan.Steps = append(plan.Steps, &OrderStep{
+				Action:    OrderActionEdit,
+				ID:        o.ID,
+				Name:      d.Name,
+				Price:     d.Price,
+				Amount:    d.Amount,
+				OldPrice:  price,
+				OldAmount: uint64(o.Amount),
+				Reason:    "price or amount differs",
+			})
+		default:
+			plan.Unchanged++
+		}
+		for _, dup := range orders[1:] {
+			price, _ := strconv.ParseUint(dup.Price, 10, 64)
+			plan.Steps = append(plan.Steps, removeStep(dup, price, "duplicate"))
+		}
+	}
+	if !m.KeepUnmanaged {
+		for _, o := range open {
+			if !wanted[o.Name] {
+				price, _ := strconv.ParseUint(o.Price, 10, 64)
+				plan.Steps = append(plan.Steps, removeStep(o, price, "not desired"))
+			}
+		}
+	}
+	return plan, nil
+}
+
+func removeStep(o *orderOpen, price uint64, reason string) *OrderStep {
+	return &OrderStep{
+		Action:    OrderActionRemove,
+		ID:        o.ID,
+		Name:      o.Name,
+		OldPrice:  price,
+		OldAmount: uint64(o.Amount),
+		Reason:    reason,
+	}
+}
+
+// Apply executes the plan, removals are sent in batches of 50.
+// The result of every step is stored in its Done and Err fields
+func (m *BuyOrderManager) Apply(plan *OrderPlan) error {
+	var removes []*OrderStep
+	var failed int
+	for _, step := range plan.Steps {
+		switch step.Action {
+		case OrderActionRemove:
+			removes = append(removes, step)
+			continue
+		case OrderActionCreate:
+			var created *orderCreateResponse
+			created, step.Err = m.Session.OrderCreate(OrderCreateConfig{Name: step.Name, Price: step.Price, Amount: step.Amount})
+			if step.Err == nil {
+				step.ID, step.Filled = created.ID, int64(created.Filled)
+			}
+		case OrderActionEdit:
+			_, step.Err = m.Session.OrderEdit(OrderEditConfig{ID: uint64(step.ID), Price: step.Price, Amount: step.Amount})
+		}
+		if step.Err != nil {
+			failed++
+			continue
+		}
+		step.Done = true
+	}
+	for start := 0; start < len(removes); start += 50 {
+		end := start + 50
+		if end > len(removes) {
+			end = len(removes)
+		}
+		ids := make([]uint64, 0, end-start)
+		for _, step := range removes[start:end] {
+			ids = append(ids, uint64(step.ID))
+		}
+		resp, err := m.Session.OrderRemove(&ids)
+		if err == nil && resp.Removed != int64(len(ids)) {
+			failed += m.checkRemoved(removes[start:end], resp.Removed)
+			continue
+		}
+		for _, step := range removes[start:end] {
+			step.Err, step.Done = err, err == nil
+		}
+		if err != nil {
+			failed += end - start
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d buy order steps failed", failed, len(plan.Steps))
+	}
+	return nil
+}
+
+// checkRemoved is called when waxpeer removed fewer orders than requested, steps of orders
+// that are still open are marked as failed. Returns the number of failed steps
+func (m *BuyOrderManager) checkRemoved(steps []*OrderStep, removed int64) int {
+	open, err := m.open()
+	if err != nil {
+		for _, step := range steps {
+			step.Err = fmt.Errorf("waxpeer removed %d of %d buy orders, checking open orders: %w", removed, len(steps), err)
+		}
+		return len(steps)
+	}
+	still := make(map[int64]bool, len(open))
+	for _, o := range open {
+		still[o.ID] = true
+	}
+	var failed int
+	for _, step := range steps {
+		if still[step.ID] {
+			step.Err = fmt.Errorf("buy order %d was not removed", step.ID)
+			failed++
+			continue
+		}
+		step.Done = true
+	}
+	return failed
+}
+
+// Sync plans and applies the desired set at once
+func (m *BuyOrderManager) Sync(desired []DesiredOrder) (*OrderPlan, error) {
+	plan, err := m.Plan(desired)
+	if err != nil {
+		return nil, err
+	}
+	return plan, m.Apply(plan)
+}
+
+func (m *BuyOrderManager) open() ([]*orderOpen, error) {
+	var all []*orderOpen
+	for skip := uint64(0); ; {
+		orders, err := m.Session.OrderOpen(OrderOpenConfig{Skip: skip})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, orders...)
+		if len(orders) < 100 {
+			return all, nil
+		}
+		skip += uint64(len(orders))
+	}
+}
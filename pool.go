@@ -0,0 +1,175 @@
+package waxpeer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PoolError holds the errors of a Pool operation by account
+type PoolError map[string]error
+
+func (e PoolError) Error() string {
+	accounts := make([]string, 0, len(e))
+	for account := range e {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	parts := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		parts = append(parts, fmt.Sprintf("%s: %v", account, e[account]))
+	}
+	return fmt.Sprintf("%d accounts failed: %s", len(e), strings.Join(parts, "; "))
+}
+
+// Pool runs operations across many accounts concurrently
+type Pool struct {
+	RateLimit   time.Duration // per-account delay between requests, applied to sessions without their own limit
+	Concurrency int           // max accounts processed at once, 0 - all
+
+	mu       sync.RWMutex
+	sessions map[string]*Session
+}
+
+// Add adds the session of an account, replacing the previous one.
+// A session without its own limit is copied to apply RateLimit, so s itself is left unchanged
+func (p *Pool) Add(account string, s *Session) {
+	p.add(account, s)
+}
+
+// AddKey creates a session for an account and returns the session the pool uses
+func (p *Pool) AddKey(account, apiKey string, opts ...Option) *Session {
+	return p.add(account, CreateSession(apiKey, opts...))
+}
+
+func (p *Pool) add(account string, s *Session) *Session {
+	if p.RateLimit != 0 && s.limiter == nil {
+		c := *s
+		c.limiter = &rateLimiter{interval: p.RateLimit}
+		s = &c
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sessions == nil {
+		p.sessions = make(map[string]*Session)
+	}
+	p.sessions[account] = s
+	return s
+}
+
+func (p *Pool) Remove(account string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.sessions, account)
+}
+
+// Session returns the session of an account or nil
+func (p *Pool) Session(account string) *Session {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.sessions[account]
+}
+
+// Accounts returns the sorted account names
+func (p *Pool) Accounts() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	accounts := make([]string, 0, len(p.sessions))
+	for account := range p.sessions {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+// Do runs fn for every account concurrently and returns the results by account,
+// the error is a PoolError with the failed accounts
+func (p *Pool) Do(fn func(account string, s *Session) (interface{}, error)) (map[string]interface{}, error) {
+	p.mu.RLock()
+	sessions := make(map[string]*Session, len(p.sessions))
+	for account, s := range p.sessions {
+		sessions[account] = s
+	}
+	p.mu.RUnlock()
+
+	limit := p.Concurrency
+	if limit <= 0 || limit > len(sessions) {
+		limit = len(sessions)
+	}
+	sem := make(chan struct{}, limit)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]interface{}, len(sessions))
+	errs := make(PoolError)
+	for account, s := range sessions {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(account string, s *Session) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			v, err := fn(account, s)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[account] = err
+				return
+			}
+			results[account] = v
+		}(account, s)
+	}
+	wg.Wait()
+	if len(errs) != 0 {
+		return results, errs
+	}
+	return results, nil
+}
+
+// AccountInformation fetches account information of all accounts
+func (p *Pool) AccountInformation() (map[string]*accountInformation, error) {
+	results, err := p.Do(func(account string, s *Session) (interface{}, error) {
+		return s.AccountInformation()
+	})
+	users := make(map[string]*accountInformation, len(results))
+	for account, v := range results {
+		users[account] = v.(*accountInformation)
+	}
+	return users, err
+}
+
+// Balance returns the total wallet of all accounts and the wallet by account
+func (p *Pool) Balance() (int64, map[string]int64, error) {
+	users, err := p.AccountInformation()
+	var total int64
+	balances := make(map[string]int64, len(users))
+	for account, user := range users {
+		balances[account] = user.Wallet
+		total += user.Wallet
+	}
+	return total, balances, err
+}
+
+// SellOrders fetches items on sale of all accounts
+func (p *Pool) SellOrders() (map[string][]*sellOrders, error) {
+	results, err := p.Do(func(account string, s *Session) (interface{}, error) {
+		return s.SellOrders()
+	})
+	orders := make(map[string][]*sellOrders, len(results))
+	for account, v := range results {
+		orders[account] = v.([]*sellOrders)
+	}
+	return orders, err
+}
+
+// OrderOpen fetches the first page of open buy orders of all accounts
+func (p *Pool) OrderOpen(c OrderOpenConfig) (map[string][]*orderOpen, error) {
+	results, err := p.Do(func(account string, s *Session) (interface{}, error) {
+		return s.OrderOpen(c)
+	})
+	orders := make(map[string][]*orderOpen, len(results))
+	for account, v := range results {
+		orders[account] = v.([]*orderOpen)
+	}
+	return orders, err
+}
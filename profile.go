@@ -1,10 +1,14 @@
 package waxpeer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/url"
 	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -32,11 +36,45 @@ var (
 )
 
 type Session struct {
-	WaxpeerApiKey string // apiKey Waxpeer
+	WaxpeerApiKey string      // apiKey Waxpeer, not used if the session has a CredentialProvider
+	PriceGuard    *PriceGuard // optional, checks prices before buying or listing
+
+	ctx             context.Context
+	credentials     CredentialProvider
+	invalidKeyHooks []func(ctx context.Context, endpoint string)
+	keyPlacement    KeyPlacement
+	keyEndpoints    map[string]bool
+	metrics         Metrics
+	tracer          trace.Tracer
+	log             *LogConfig
+	middleware      []Middleware
+	limiter         *rateLimiter
+	cache           *Cache
+	noCompression   bool
+	retries         int
+	retryBackoff    time.Duration
+}
+
+func CreateSession(WaxpeerApiKey string, opts ...Option) *Session {
+	s := &Session{WaxpeerApiKey: WaxpeerApiKey}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func CreateSession(WaxpeerApiKey string) *Session {
-	return &Session{WaxpeerApiKey: WaxpeerApiKey}
+// WithContext returns a copy of the session whose requests use ctx for cancellation and tracing
+func (s *Session) WithContext(ctx context.Context) *Session {
+	c := *s
+	c.ctx = ctx
+	return &c
+}
+
+func (s *Session) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
 }
 
 //Get Account Information
@@ -44,7 +82,7 @@ func (s *Session) AccountInformation() (*accountInformation, error) {
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
-	b, err := Get(profileAccountInformation + bodyRequest.Encode())
+	b, err := s.get(profileAccountInformation, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +108,7 @@ func (s *Session) OrderOpen(c OrderOpenConfig) ([]*orderOpen, error) {
 		"name": {c.Name},
 		"skip": {strconv.FormatUint(c.Skip, 10)},
 	}
-	b, err := Get(profileYourBuyOrders + bodyRequest.Encode())
+	b, err := s.get(profileYourBuyOrders, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +128,7 @@ func (s *Session) AccountSetSteamApiKey(steamApiKey string) error {
 		"api":       {s.WaxpeerApiKey},
 		"steam_api": {steamApiKey},
 	}
-	b, err := Get(profileSetSteamApiKey + bodyRequest.Encode())
+	b, err := s.get(profileSetSteamApiKey, bodyRequest)
 	if err != nil {
 		return err
 	}
@@ -105,23 +143,23 @@ func (s *Session) AccountSetSteamApiKey(steamApiKey string) error {
 }
 
 // Set Steam Tradelink
-func (s *Session) AccountSetTradelink(tradelink string) error {
+func (s *Session) AccountSetTradelink(tradelink string) (*accountSetTradelinkResponse, error) {
 	bodyRequest := url.Values{
 		"api":       {s.WaxpeerApiKey},
 		"tradelink": {tradelink},
 	}
-	b, err := Post(profileChangeTradelink+bodyRequest.Encode(), nil)
+	b, err := s.post(profileChangeTradelink, bodyRequest, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var body accountSetTradelinkResponse
 	if err = json.Unmarshal(*b, &body); err != nil {
-		return err
+		return nil, err
 	}
 	if body.Success != true {
-		return wrongApiKey
+		return nil, wrongApiKey
 	}
-	return nil
+	return &body, nil
 }
 
 type AccountTransferConfig struct {
@@ -136,7 +174,7 @@ func (s *Session) AccountTransfer(c AccountTransferConfig) error {
 		"steam_id": {strconv.FormatUint(c.SteamId, 10)},
 		"amount":   {strconv.FormatUint(c.Amount, 10)},
 	}
-	b, err := Post(profileSendBalance+bodyRequest.Encode(), nil)
+	b, err := s.post(profileSendBalance, bodyRequest, nil)
 	if err != nil {
 		return err
 	}
@@ -152,46 +190,47 @@ func (s *Session) AccountTransfer(c AccountTransferConfig) error {
 
 // Orders
 // Remove buy orders // MAX 50
-func (s *Session) OrderRemove(idArray *[]uint64) error {
+func (s *Session) OrderRemove(idArray *[]uint64) (*orderRemoveResponse, error) {
 	if len(*idArray) > 50 {
-		return max50Elements
+		return nil, max50Elements
 	}
 	bodyRequest := url.Values{"api": {s.WaxpeerApiKey}}
 	for _, id := range *idArray {
 		bodyRequest.Add("id", strconv.FormatUint(id, 10))
 	}
-	b, err := Get(profileRemoveBuyOrder + bodyRequest.Encode())
+	b, err := s.get(profileRemoveBuyOrder, bodyRequest)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var body orderRemoveResponse
 	if err = json.Unmarshal(*b, &body); err != nil {
-		return err
+		return nil, err
 	}
 	if body.Success != true {
-		return errors.New(body.Msg)
+		return nil, errors.New(body.Msg)
 	}
-	return nil
+	return &body, nil
 }
 
-// remove all buy orders
-func (s *Session) OrderRemoveAll() error {
+// remove all buy orders, the response is nil if waxpeer answers with an empty body
+// as then the number of removed orders is unknown
+func (s *Session) OrderRemoveAll() (*orderRemoveAllresponse, error) {
 	bodyRequest := url.Values{"api": {s.WaxpeerApiKey}}
-	b, err := Get(profileRemoveAllOrders + bodyRequest.Encode())
+	b, err := s.get(profileRemoveAllOrders, bodyRequest)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(*b) == 0 {
-		return nil
+		return nil, nil
 	}
 	var body orderRemoveAllresponse
 	if err = json.Unmarshal(*b, &body); err != nil {
-		return err
+		return nil, err
 	}
 	if body.Success != true {
-		return wrongApiKey
+		return nil, wrongApiKey
 	}
-	return nil
+	return &body, nil
 }
 
 type OrderHistoryConfig struct {
@@ -204,7 +243,7 @@ func (s *Session) OrderHistory(c OrderHistoryConfig) ([]*orderHistory, error) {
 		"api":  {s.WaxpeerApiKey},
 		"skip": {strconv.FormatUint(c.Skip, 10)},
 	}
-	b, err := Get(profileBuyOrderHistory + bodyRequest.Encode())
+	b, err := s.get(profileBuyOrderHistory, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -225,26 +264,26 @@ type OrderEditConfig struct {
 }
 
 // edit buy order
-func (s *Session) OrderEdit(c OrderEditConfig) error {
+func (s *Session) OrderEdit(c OrderEditConfig) (*orderEditResponse, error) {
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
 	bodyRequestJson, err := json.Marshal(c)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	b, err := Post(profileEditBuyOrder+bodyRequest.Encode(), bodyRequestJson)
+	b, err := s.post(profileEditBuyOrder, bodyRequest, bodyRequestJson)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var body orderEditResponse
 	if err = json.Unmarshal(*b, &body); err != nil {
-		return err
+		return nil, err
 	}
 	if body.Success != true {
-		return errors.New(body.Msg)
+		return nil, errors.New(body.Msg)
 	}
-	return nil
+	return &body, nil
 }
 
 type OrderCreateConfig struct {
@@ -254,25 +293,30 @@ type OrderCreateConfig struct {
 }
 
 // create buy order
-func (s *Session) OrderCreate(c OrderCreateConfig) (int64, error) {
+func (s *Session) OrderCreate(c OrderCreateConfig) (*orderCreateResponse, error) {
+	if s.PriceGuard != nil {
+		if err := s.PriceGuard.checkBuy(s, 0, c.Name, int64(c.Price)); err != nil {
+			return nil, err
+		}
+	}
 	bodyRequest := url.Values{
 		"api":    {s.WaxpeerApiKey},
 		"name":   {c.Name},
 		"price":  {strconv.FormatUint(c.Price, 10)},
 		"amount": {strconv.FormatUint(c.Amount, 10)},
 	}
-	b, err := Post(profileCreateBuyOrder+bodyRequest.Encode(), nil)
+	b, err := s.post(profileCreateBuyOrder, bodyRequest, nil)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	var body orderCreateResponse
 	if err = json.Unmarshal(*b, &body); err != nil {
-		return 0, err
+		return nil, err
 	}
 	if body.Success != true {
-		return 0, errors.New(body.Msg)
+		return nil, errors.New(body.Msg)
 	}
-	return body.ID, nil
+	return &body, nil
 }
 
 type AccountHistoryConfig struct {
@@ -289,7 +333,7 @@ func (s *Session) AccountHistory(c AccountHistoryConfig) ([]*accountHistory, err
 		"token":   {c.Token},
 		"skip":    {strconv.FormatUint(c.Skip, 10)},
 	}
-	b, err := Get(profileHistory + bodyRequest.Encode())
+	b, err := s.get(profileHistory, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,275 @@
+package waxpeer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RepriceItem is a listed item passed to a RepriceStrategy
+type RepriceItem struct {
+	ItemID       ItemID
+	Name         string
+	Price        int64 // current price of the listing
+	SteamAverage int64 // steam_price.average of the listing
+	Lowest       int64 // lowest competitor price, our own listings are not competitors, 0 if there are none
+	IsLowest     bool  // our listing is cheaper than every competitor
+}
+
+// RepriceStrategy returns a new price for the item and the reason of the change,
+// ok = false leaves the price as it is
+type RepriceStrategy interface {
+	Reprice(item *RepriceItem) (price int64, reason string, ok bool)
+}
+
+// RepriceFunc allows to use an ordinary function as a RepriceStrategy
+type RepriceFunc func(item *RepriceItem) (int64, string, bool)
+
+func (f RepriceFunc) Reprice(item *RepriceItem) (int64, string, bool) {
+	return f(item)
+}
+
+// UndercutStrategy sets the price Step units below the lowest competitor
+type UndercutStrategy struct {
+	Step int64 // 1$ = 1000
+}
+
+func (u UndercutStrategy) Reprice(item *RepriceItem) (int64, string, bool) {
+	if item.IsLowest || item.Lowest == 0 {
+		return 0, "", false
+	}
+	return item.Lowest - u.Step, fmt.Sprintf("undercut lowest price %d by %d", item.Lowest, u.Step), true
+}
+
+// SteamStrategy sets the price Discount percent below the steam average price
+type SteamStrategy struct {
+	Discount float64 // ex: 5 is 5% below steam
+}
+
+func (st SteamStrategy) Reprice(item *RepriceItem) (int64, string, bool) {
+	if item.SteamAverage == 0 {
+		return 0, "", false
+	}
+	price := int64(float64(item.SteamAverage) * (100 - st.Discount) / 100)
+	return price, fmt.Sprintf("steam average %d minus %.2f%%", item.SteamAverage, st.Discount), true
+}
+
+// LimitStrategy keeps the price of the wrapped strategy between floor and ceiling,
+// limits by item name take precedence over the default ones
+type LimitStrategy struct {
+	Strategy RepriceStrategy
+	Floor    int64            // default floor, 0 - no floor
+	Ceiling  int64            // default ceiling, 0 - no ceiling
+	Floors   map[string]int64 // floor by item name
+	Ceilings map[string]int64 // ceiling by item name
+}
+
+func (l LimitStrategy) Reprice(item *RepriceItem) (int64, string, bool) {
+	price, reason, ok := l.Strategy.Reprice(item)
+	if !ok {
+		return 0, "", false
+	}
+	floor, ceiling := l.Floor, l.Ceiling
+	if v, ok := l.Floors[item.Name]; ok {
+		floor = v
+	}
+	if v, ok := l.Ceilings[item.Name]; ok {
+		ceiling = v
+	}
+	if floor != 0 && price < floor {
+		price, reason = floor, fmt.Sprintf("%s, raised to floor %d", reason, floor)
+	}
+	if ceiling != 0 && price > ceiling {
+		price, reason = ceiling, fmt.Sprintf("%s, lowered to ceiling %d", reason, ceiling)
+	}
+	return price, reason, true
+}
+
+type RepriceChange struct {
+	ItemID   ItemID
+	Name     string
+	OldPrice int64
+	NewPrice int64
+	Reason   string
+	Applied  bool
+	Error    string // message from waxpeer if the change failed
+}
+
+type RepriceReport struct {
+	Started   time.Time
+	Finished  time.Time
+	Checked   int // number of listings checked
+	Unchanged int
+	Changes   []*RepriceChange
+}
+
+// Repricer keeps listed items competitive: reads SellOrders, checks competitors with PricesName,
+// asks Strategy for new prices and applies them with SellEdit in batches of 50
+type Repricer struct {
+	Session  *Session
+	Strategy RepriceStrategy
+	Interval time.Duration                // delay between runs in Run, default 1 minute
+	DryRun   bool                         // build the report without calling SellEdit
+	Filter   func(item *RepriceItem) bool // optional, only items for which it returns true are repriced
+	OnReport func(r *RepriceReport)       // called after every run in Run
+	OnError  func(err error)              // if set, Run reports errors here and keeps running
+}
+
+// Run reprices items every Interval until ctx is done, ctx also cancels a running repricing
+func (r *Repricer) Run(ctx context.Context) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	session := r.Session.WithContext(ctx)
+	for {
+		report, err := r.runOnce(ctx, session)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && r.OnError == nil:
+			return err
+		case err != nil:
+			r.OnError(err)
+		case r.OnReport != nil:
+			r.OnReport(report)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce reprices all listed items once
+func (r *Repricer) RunOnce() (*RepriceReport, error) {
+	return r.runOnce(r.Session.context(), r.Session)
+}
+
+func (r *Repricer) runOnce(ctx context.Context, session *Session) (*RepriceReport, error) {
+	report := &RepriceReport{Started: time.Now()}
+	orders, err := session.SellOrders()
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.items(session, orders)
+	if err != nil {
+		return nil, err
+	}
+	report.Checked = len(items)
+	var edits []SellItemConfig
+	for _, item := range items {
+		if r.Filter != nil && !r.Filter(item) {
+			report.Unchanged++
+			continue
+		}
+		price, reason, ok := r.Strategy.Reprice(item)
+		if !ok || price == item.Price || price <= 0 {
+			report.Unchanged++
+			continue
+		}
+		report.Changes = append(report.Changes, &RepriceChange{
+			ItemID:   item.ItemID,
+			Name:     item.Name,
+			OldPrice: item.Price,
+			NewPrice: price,
+			Reason:   reason,
+		})
+		edits = append(edits, SellItemConfig{ItemID: item.ItemID, Price: price})
+	}
+	if !r.DryRun {
+		if err = r.apply(ctx, session, edits, report.Changes); err != nil {
+			return report, err
+		}
+	}
+	report.Finished = time.Now()
+	return report, nil
+}
+
+func (r *Repricer) items(session *Session, orders []*sellOrders) ([]*RepriceItem, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, o := range orders {
+		if !seen[o.Name] {
+			seen[o.Name] = true
+			names = append(names, o.Name)
+		}
+	}
+	own := make(map[ItemID]bool, len(orders))
+	for _, o := range orders {
+		own[o.ItemID] = true
+	}
+	lowest := make(map[string]*priceName)
+	for start := 0; start < len(names); start += 100 {
+		end := start + 100
+		if end > len(names) {
+			end = len(names)
+		}
+		batch := names[start:end]
+		prices, err := session.PricesName(&batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range prices {
+			if own[p.ItemID] {
+				continue
+			}
+			if l, ok := lowest[p.Name]; !ok || p.Price < l.Price {
+				lowest[p.Name] = p
+			}
+		}
+	}
+	items := make([]*RepriceItem, 0, len(orders))
+	for _, o := range orders {
+		item := &RepriceItem{
+			ItemID:       o.ItemID,
+			Name:         o.Name,
+			Price:        int64(o.Price),
+			SteamAverage: int64(o.SteamPrice.Average),
+		}
+		item.IsLowest = true
+		if l, ok := lowest[o.Name]; ok {
+			item.Lowest = l.Price
+			item.IsLowest = l.Price > item.Price
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// apply sends the edits in batches, changes of batches that are not sent get the error
+func (r *Repricer) apply(ctx context.Context, session *Session, edits []SellItemConfig, changes []*RepriceChange) error {
+	failed := make(map[ItemID]string)
+	for start := 0; start < len(edits); start += 50 {
+		end := start + 50
+		if end > len(edits) {
+			end = len(edits)
+		}
+		batch := edits[start:end]
+		err := ctx.Err()
+		var resp *sellEditResponse
+		if err == nil {
+			resp, err = session.SellEdit(&batch)
+		}
+		if err != nil {
+			for _, c := range changes[start:] {
+				c.Error = err.Error()
+			}
+			return err
+		}
+		for _, f := range resp.Failed {
+			failed[f.ItemID] = f.Msg
+		}
+		for _, c := range changes[start:end] {
+			if msg, ok := failed[c.ItemID]; ok {
+				c.Error = msg
+				continue
+			}
+			c.Applied = true
+		}
+	}
+	return nil
+}
@@ -1,31 +1,435 @@
 package waxpeer
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ErrorKindTransport = "transport" // request was not completed
+	ErrorKindStatus    = "status"    // http status is not 2xx
+	ErrorKindApi       = "api"       // waxpeer returned success: false
 )
 
+// Metrics receives measurements of every request made by a Session, see WithMetrics
+type Metrics interface {
+	ObserveRequest(endpoint string, duration time.Duration, errorKind string) // errorKind is empty on success
+	ObserveRateLimitWait(endpoint string, wait time.Duration)
+	ObserveRetry(endpoint string)
+}
+
+// read-only endpoints are retried after transport errors and 5xx responses, others only after 429
+var readOnlyEndpoints = map[string]bool{
+	"user":                  true,
+	"history":               true,
+	"buy-orders":            true,
+	"buy-order-history":     true,
+	"get-steam-items":       true,
+	"prices":                true,
+	"check-availability":    true,
+	"get-items-list":        true,
+	"get-my-inventory":      true,
+	"search-items-by-name":  true,
+	"check-many-project-id": true,
+}
+
 func Get(url string) (*[]byte, error) {
-	request := fasthttp.AcquireRequest()
-	request.Header.SetRequestURI(url)
-	request.Header.SetMethod("GET")
+	b, _, err := send(context.Background(), "GET", url, nil, nil)
+	return b, err
+}
+
+func Post(url string, body []byte) (*[]byte, error) {
+	b, _, err := send(context.Background(), "POST", url, nil, body)
+	return b, err
+}
+
+// responses larger than streamBuffer are read from the connection as they are decoded by sendStream
+const streamBuffer = 64 << 10
+
+var (
+	client       = &fasthttp.Client{}
+	streamClient = &fasthttp.Client{StreamResponseBody: true, MaxResponseBodySize: streamBuffer}
+)
+
+func send(ctx context.Context, method, url string, header map[string]string, body []byte) (*[]byte, int, error) {
+	request := httpRequest(method, url, header, body)
 	response := fasthttp.AcquireResponse()
-	if err := fasthttp.Do(request, response); err != nil {
-		return nil, err
+	if err := doRequest(ctx, client, request, response); err != nil {
+		return nil, 0, err
+	}
+	b, err := responseBody(response)
+	if err != nil {
+		return nil, 0, err
 	}
-	b := response.Body()
-	return &b, nil
+	return &b, response.StatusCode(), nil
 }
 
-func Post(url string, body []byte) (*[]byte, error) {
+// sendStream is send that passes the body of 2xx responses to read while it is received instead of buffering it
+func sendStream(ctx context.Context, method, url string, header map[string]string, read func(r io.Reader)) (int, error) {
+	request := httpRequest(method, url, header, nil)
+	response := fasthttp.AcquireResponse()
+	if err := doRequest(ctx, streamClient, request, response); err != nil {
+		return 0, err
+	}
+	defer fasthttp.ReleaseRequest(request)
+	defer fasthttp.ReleaseResponse(response)
+	status := response.StatusCode()
+	if status < 200 || status > 299 {
+		return status, nil
+	}
+	r, err := bodyReader(response)
+	if err != nil {
+		return 0, err
+	}
+	read(ctxReader{ctx: ctx, r: r})
+	return status, nil
+}
+
+func httpRequest(method, url string, header map[string]string, body []byte) *fasthttp.Request {
 	request := fasthttp.AcquireRequest()
 	request.Header.SetRequestURI(url)
-	request.Header.SetMethod("POST")
-	request.Header.SetContentType("application/json")
-	request.SetBody(body)
-	response := fasthttp.AcquireResponse()
-	if err := fasthttp.Do(request, response); err != nil {
+	request.Header.SetMethod(method)
+	request.Header.Set("Accept-Encoding", "gzip, deflate")
+	for k, v := range header {
+		request.Header.Set(k, v)
+	}
+	if method == "POST" {
+		request.Header.SetContentType("application/json")
+		request.SetBody(body)
+	}
+	return request
+}
+
+// doRequest sends the request and returns ctx.Err() as soon as ctx is done. The abandoned request
+// and response are released when the client is finished with them, the caller must not use them
+func doRequest(ctx context.Context, c *fasthttp.Client, request *fasthttp.Request, response *fasthttp.Response) error {
+	if ctx.Done() == nil {
+		return c.Do(request, response)
+	}
+	done := make(chan error, 1)
+	go func() {
+		if deadline, ok := ctx.Deadline(); ok {
+			done <- c.DoDeadline(request, response, deadline)
+			return
+		}
+		done <- c.Do(request, response)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		go func() {
+			<-done
+			fasthttp.ReleaseRequest(request)
+			fasthttp.ReleaseResponse(response)
+		}()
+		return ctx.Err()
+	}
+}
+
+// ctxReader stops reading a streamed body once ctx is done
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// responseBody returns the body decoded according to Content-Encoding
+func responseBody(response *fasthttp.Response) ([]byte, error) {
+	switch contentEncoding(response) {
+	case "gzip":
+		return response.BodyGunzip()
+	case "deflate":
+		return response.BodyInflate()
+	}
+	return response.Body(), nil
+}
+
+// bodyReader returns the body stream decoded according to Content-Encoding
+func bodyReader(response *fasthttp.Response) (io.Reader, error) {
+	r := response.BodyStream()
+	switch contentEncoding(response) {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		return zlib.NewReader(r)
+	}
+	return r, nil
+}
+
+func contentEncoding(response *fasthttp.Response) string {
+	return string(bytes.ToLower(bytes.TrimSpace(response.Header.Peek("Content-Encoding"))))
+}
+
+// endpointName returns the path of the endpoint url, ex: get-items-list
+func endpointName(endpoint string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(endpoint, defaultURL), "?")
+}
+
+func (s *Session) get(endpoint string, params url.Values) (*[]byte, error) {
+	ctx := s.context()
+	var resp *Response
+	var err error
+	if s.cache != nil {
+		resp, err = s.cache.get(ctx, endpointName(endpoint), params, func(ctx context.Context) (*Response, error) {
+			return s.do(ctx, "GET", endpoint, params, nil)
+		})
+	} else {
+		resp, err = s.do(ctx, "GET", endpoint, params, nil)
+	}
+	if err != nil {
 		return nil, err
 	}
-	b := response.Body()
-	return &b, nil
+	return &resp.Body, nil
+}
+
+func (s *Session) post(endpoint string, params url.Values, body []byte) (*[]byte, error) {
+	resp, err := s.do(s.context(), "POST", endpoint, params, body)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Body, nil
+}
+
+func (s *Session) do(ctx context.Context, method, endpoint string, params url.Values, body []byte) (*Response, error) {
+	h := Handler(s.roundTrip)
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+	return s.withKey(ctx, endpoint, func(key string) (*Response, error) {
+		return h(ctx, s.newRequest(method, endpoint, params, body, key))
+	})
+}
+
+// withKey calls send with the api key, and once more with a new key if waxpeer rejects it and the provider has one
+func (s *Session) withKey(ctx context.Context, endpoint string, send func(key string) (*Response, error)) (*Response, error) {
+	name := endpointName(endpoint)
+	for rotated := false; ; rotated = true {
+		key, err := s.apiKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := send(key)
+		if err != nil {
+			return nil, s.requestError(name, key, err)
+		}
+		checkKey := s.credentials != nil || len(s.invalidKeyHooks) != 0
+		if checkKey && invalidKey(resp) && s.keyRejected(ctx, name, key) && !rotated {
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// newRequest builds the request with the api key placed according to the session
+func (s *Session) newRequest(method, endpoint string, params url.Values, body []byte, key string) *Request {
+	req := &Request{
+		Method:   method,
+		Endpoint: endpointName(endpoint),
+		Params:   params,
+		Body:     body,
+		Header:   make(map[string]string),
+		key:      key,
+	}
+	if s.noCompression {
+		req.Header["Accept-Encoding"] = "identity"
+	}
+	s.placeKey(req, key)
+	return req
+}
+
+// roundTrip is the last Handler of the middleware chain, it sends the request with rate limiting and retries
+func (s *Session) roundTrip(ctx context.Context, req *Request) (*Response, error) {
+	name := req.Endpoint
+	var span trace.Span
+	if s.tracer != nil {
+		ctx, span = s.tracer.Start(ctx, "waxpeer "+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+			attribute.String("waxpeer.endpoint", name),
+			attribute.Int("waxpeer.items", requestItems(req.Params, req.Body)),
+		))
+		defer span.End()
+	}
+	start := time.Now()
+	if s.log != nil {
+		s.log.request(ctx, req.key, req.Method, name, req.Params, req.Body)
+	}
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			endSpan(span, ErrorKindTransport, err)
+			if s.log != nil {
+				s.log.response(ctx, req.key, name, nil, ErrorKindTransport, err, attempt, time.Since(start))
+			}
+			return nil, err
+		}
+		if s.limiter != nil {
+			wait, err := s.limiter.wait(ctx)
+			if s.metrics != nil {
+				s.metrics.ObserveRateLimitWait(name, wait)
+			}
+			if err != nil {
+				continue
+			}
+		}
+		resp, err := s.attempt(ctx, req)
+		kind := errorKind(resp, err)
+		if attempt < s.retries && retryable(name, resp, err) {
+			if s.metrics != nil {
+				s.metrics.ObserveRetry(name)
+			}
+			sleep(ctx, s.retryBackoff*time.Duration(attempt+1))
+			continue
+		}
+		if span != nil {
+			span.SetAttributes(attribute.Int("waxpeer.attempts", attempt+1))
+			endSpan(span, kind, err)
+		}
+		if s.log != nil {
+			s.log.response(ctx, req.key, name, resp, kind, err, attempt+1, time.Since(start))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// attempt sends one http request
+func (s *Session) attempt(ctx context.Context, req *Request) (*Response, error) {
+	var span trace.Span
+	if s.tracer != nil {
+		ctx, span = s.tracer.Start(ctx, "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+			attribute.String("http.method", req.Method),
+			attribute.String("waxpeer.endpoint", req.Endpoint),
+		))
+	}
+	start := time.Now()
+	var resp *Response
+	var b *[]byte
+	var status int
+	var err error
+	if req.stream != nil {
+		streamed := &Response{}
+		status, err = sendStream(ctx, req.Method, req.url(), req.Header, func(r io.Reader) {
+			req.stream(r, streamed)
+		})
+		if err == nil {
+			resp = streamed
+			resp.StatusCode = status
+		}
+	} else {
+		b, status, err = send(ctx, req.Method, req.url(), req.Header, req.Body)
+		if err == nil {
+			resp = &Response{StatusCode: status, Body: *b}
+		}
+	}
+	kind := errorKind(resp, err)
+	if s.metrics != nil {
+		s.metrics.ObserveRequest(req.Endpoint, time.Since(start), kind)
+	}
+	if span != nil {
+		if status != 0 {
+			span.SetAttributes(attribute.Int("http.status_code", status))
+		}
+		endSpan(span, kind, err)
+		span.End()
+	}
+	return resp, err
+}
+
+// endSpan records the outcome of a request on the span, the url is never recorded as it contains the api key
+func endSpan(span trace.Span, kind string, err error) {
+	if span == nil || kind == "" {
+		return
+	}
+	span.SetAttributes(attribute.String("waxpeer.error_kind", kind))
+	msg := kind
+	if err != nil {
+		msg = err.Error()
+	}
+	span.SetStatus(codes.Error, msg)
+}
+
+// requestItems counts items passed in the query or in the json body
+func requestItems(params url.Values, body []byte) int {
+	n := len(params["item_id"]) + len(params["id"]) + len(params["names"])
+	return n + bytes.Count(body, []byte(`"item_id"`))
+}
+
+func errorKind(resp *Response, err error) string {
+	switch {
+	case err != nil:
+		return ErrorKindTransport
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return ErrorKindStatus
+	case resp.failed():
+		return ErrorKindApi
+	}
+	return ""
+}
+
+func retryable(endpoint string, resp *Response, err error) bool {
+	if err != nil {
+		return readOnlyEndpoints[endpoint]
+	}
+	if resp.StatusCode == fasthttp.StatusTooManyRequests {
+		return true
+	}
+	return readOnlyEndpoints[endpoint] && resp.StatusCode >= 500
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimiter spaces requests at least interval apart
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next request is allowed or ctx is done and returns the time spent waiting
+func (l *rateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	d := at.Sub(now)
+	if err := sleep(ctx, d); err != nil {
+		return time.Since(now), err
+	}
+	return d, nil
 }
@@ -0,0 +1,29 @@
+package waxpeer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestWithContextCancel(t *testing.T) {
+	body := pricesBody(t, 10)
+	fakeServer(t, func(ctx *fasthttp.RequestCtx) {
+		time.Sleep(500 * time.Millisecond)
+		ctx.SetBody(body)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := CreateSession("key").WithContext(ctx).Prices(PricesConfig{Game: GameCSGO})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 200*time.Millisecond {
+		t.Fatalf("returned after %v, want soon after cancel", d)
+	}
+}
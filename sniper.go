@@ -0,0 +1,341 @@
+package waxpeer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	sniperBudgetSpent = errors.New("sniper budget is spent")
+	sniperBuyLimit    = errors.New("sniper buy limit reached")
+)
+
+// SnipeCandidate is a listing from PricesFilter checked against the sniper rules
+type SnipeCandidate struct {
+	ItemID     ItemID
+	Name       string
+	Brand      string
+	Type       string
+	Price      int64
+	SteamPrice int64
+	Discount   int64
+	BestDeals  int64
+	Float      float64
+}
+
+type SnipeRule interface {
+	Match(c *SnipeCandidate) bool
+}
+
+// SnipeRuleFunc allows to use an ordinary function as a SnipeRule
+type SnipeRuleFunc func(c *SnipeCandidate) bool
+
+func (f SnipeRuleFunc) Match(c *SnipeCandidate) bool {
+	return f(c)
+}
+
+// FloatRule matches items with float between Min and Max, Max = 0 - no upper bound
+type FloatRule struct {
+	Min float64
+	Max float64
+}
+
+func (r FloatRule) Match(c *SnipeCandidate) bool {
+	return c.Float >= r.Min && (r.Max == 0 || c.Float <= r.Max)
+}
+
+// SteamRatioRule matches items cheaper than MaxRatio percent of the steam price, ex: 70
+type SteamRatioRule struct {
+	MaxRatio float64
+}
+
+func (r SteamRatioRule) Match(c *SnipeCandidate) bool {
+	return c.SteamPrice > 0 && float64(c.Price)/float64(c.SteamPrice)*100 <= r.MaxRatio
+}
+
+// NameRule matches items with one of the names
+type NameRule map[string]bool
+
+func (r NameRule) Match(c *SnipeCandidate) bool {
+	return r[c.Name]
+}
+
+type SnipeResult struct {
+	Candidate *SnipeCandidate
+	Err       error         // error from BuyID, nil if the item was bought
+	Detected  time.Duration // time from the start of the poll until the item was matched
+	Latency   time.Duration // time from the start of the poll until BuyID returned
+}
+
+type SniperStats struct {
+	Polls       int64
+	Candidates  int64 // items matched by the rules
+	Unavailable int64 // matched items sold before ItemAvailable check
+	Bought      int64
+	Failed      int64
+	Spent       int64
+	LastPoll    time.Duration // duration of the last PricesFilter call
+	AvgLatency  time.Duration // average time from poll start to BuyID response
+}
+
+// Sniper polls PricesFilter and buys items that match all Rules
+type Sniper struct {
+	Session   *Session
+	Filter    PricesFilterConfig
+	Rules     []SnipeRule
+	Interval  time.Duration                  // delay between polls, default 2 seconds
+	Partner   string                         // partner parameter from steam tradelink
+	Token     string                         // token parameter from steam tradelink
+	ProjectId func(c *SnipeCandidate) string // optional, your unique ID for the trade
+	MaxPrice  int64                          // guardrail, never buy items more expensive than this, 0 - no limit
+	Budget    int64                          // guardrail, total amount the sniper may spend, 0 - no limit
+	MaxBuys   int64                          // guardrail, number of items the sniper may buy, 0 - no limit
+	SeenTTL   time.Duration                  // how long a bought or unavailable item is not checked again, default 10 minutes
+	OnResult  func(r *SnipeResult)
+	OnError   func(err error) // if set, Run reports poll errors here and keeps running
+
+	mu    sync.Mutex
+	seen  map[ItemID]time.Time
+	stats SniperStats
+}
+
+// Stats returns a copy of the sniper counters
+func (s *Sniper) Stats() SniperStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Run polls every Interval until ctx is done or a guardrail stops the sniper, ctx also cancels a running poll
+func (s *Sniper) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	session := s.Session.WithContext(ctx)
+	for {
+		err := s.poll(ctx, session)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err == sniperBudgetSpent || err == sniperBuyLimit:
+			return err
+		case err != nil && s.OnError == nil:
+			return err
+		case err != nil:
+			s.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches listings once and buys the matching ones
+func (s *Sniper) Poll() error {
+	return s.poll(s.Session.context(), s.Session)
+}
+
+func (s *Sniper) poll(ctx context.Context, session *Session) error {
+	if err := s.limitReached(); err != nil {
+		return err
+	}
+	s.forgetSeen()
+	start := time.Now()
+	filter := s.Filter
+	if s.usesFloat() {
+		filter.Minified = true
+	}
+	items, err := session.PricesFilter(filter)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.stats.Polls++
+	s.stats.LastPoll = time.Since(start)
+	s.mu.Unlock()
+
+	var matched []*SnipeCandidate
+	for _, item := range items {
+		c := &SnipeCandidate{
+			ItemID:     item.ItemID,
+			Name:       item.Name,
+			Brand:      item.Brand,
+			Type:       item.Type,
+			Price:      item.Price,
+			SteamPrice: item.SteamPrice,
+			Discount:   item.Discount,
+			BestDeals:  item.BestDeals,
+			Float:      item.Float,
+		}
+		if s.MaxPrice != 0 && c.Price > s.MaxPrice || !s.match(c) || s.seenRecently(c.ItemID) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	detected := time.Since(start)
+	s.mu.Lock()
+	s.stats.Candidates += int64(len(matched))
+	s.mu.Unlock()
+
+	available, err := s.available(session, matched)
+	if err != nil {
+		return err
+	}
+	for _, c := range matched {
+		if p, ok := available[c.ItemID]; !ok || p > c.Price {
+			s.mu.Lock()
+			s.stats.Unavailable++
+			s.mu.Unlock()
+			s.markSeen(c.ItemID)
+			continue
+		}
+		if err = s.limitReached(); err != nil {
+			return err
+		}
+		if !s.affordable(c.Price) {
+			continue
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if s.buy(session, c, start, detected) == nil {
+			s.markSeen(c.ItemID)
+		}
+	}
+	return nil
+}
+
+func (s *Sniper) buy(session *Session, c *SnipeCandidate, start time.Time, detected time.Duration) error {
+	conf := BuyIDConfig{
+		ItemId:  c.ItemID,
+		Price:   uint64(c.Price),
+		Partner: s.Partner,
+		Token:   s.Token,
+	}
+	if s.ProjectId != nil {
+		conf.ProjectId = s.ProjectId(c)
+	}
+	err := session.BuyID(conf)
+	result := &SnipeResult{Candidate: c, Err: err, Detected: detected, Latency: time.Since(start)}
+	s.mu.Lock()
+	if err != nil {
+		s.stats.Failed++
+	} else {
+		s.stats.Bought++
+		s.stats.Spent += c.Price
+		s.stats.AvgLatency += (result.Latency - s.stats.AvgLatency) / time.Duration(s.stats.Bought)
+	}
+	s.mu.Unlock()
+	if s.OnResult != nil {
+		s.OnResult(result)
+	}
+	return err
+}
+
+// available returns the current price of items that are still on sale
+func (s *Sniper) available(session *Session, matched []*SnipeCandidate) (map[ItemID]int64, error) {
+	available := make(map[ItemID]int64)
+	for start := 0; start < len(matched); start += 100 {
+		end := start + 100
+		if end > len(matched) {
+			end = len(matched)
+		}
+		ids := make([]ItemID, 0, end-start)
+		for _, c := range matched[start:end] {
+			ids = append(ids, c.ItemID)
+		}
+		items, err := session.ItemAvailable(&ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Selling {
+				available[item.ItemID] = item.Price
+			}
+		}
+	}
+	return available, nil
+}
+
+func (s *Sniper) match(c *SnipeCandidate) bool {
+	for _, rule := range s.Rules {
+		if !rule.Match(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Sniper) usesFloat() bool {
+	for _, rule := range s.Rules {
+		if _, ok := rule.(FloatRule); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// seenRecently reports whether the item was bought or found unavailable within SeenTTL
+func (s *Sniper) seenRecently(id ItemID) bool {
+	ttl := s.SeenTTL
+	if ttl == 0 {
+		ttl = 10 * time.Minute
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.seen[id]
+	return ok && time.Since(t) < ttl
+}
+
+// markSeen skips the item in the polls within SeenTTL, only items that are bought or not on sale are marked
+// so listings are checked again after an error
+func (s *Sniper) markSeen(id ItemID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = make(map[ItemID]time.Time)
+	}
+	s.seen[id] = time.Now()
+}
+
+func (s *Sniper) forgetSeen() {
+	ttl := s.SeenTTL
+	if ttl == 0 {
+		ttl = 10 * time.Minute
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, t := range s.seen {
+		if time.Since(t) >= ttl {
+			delete(s.seen, id)
+		}
+	}
+}
+
+func (s *Sniper) limitReached() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Budget != 0 && s.stats.Spent >= s.Budget {
+		return sniperBudgetSpent
+	}
+	if s.MaxBuys != 0 && s.stats.Bought >= s.MaxBuys {
+		return sniperBuyLimit
+	}
+	return nil
+}
+
+func (s *Sniper) affordable(price int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Budget == 0 || s.stats.Spent+price <= s.Budget
+}
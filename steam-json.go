@@ -4,13 +4,14 @@ import "time"
 
 type getSteamItemsResponse struct {
 	Success bool         `json:"success"`
-	Items   []*steamItem `json:"items"`
+	Items   []*SteamItem `json:"items"`
 }
 
-type steamItem struct {
+// SteamItem is an item of PricesSteam
+type SteamItem struct {
 	Name       string      `json:"name"`
 	Average    int64       `json:"average"`
-	GameID     int64       `json:"game_id"`
+	GameID     Game        `json:"game_id"`
 	Type       interface{} `json:"type"`
 	Collection interface{} `json:"collection"`
 	RuName     interface{} `json:"ru_name"`
@@ -36,10 +37,11 @@ type buyresponse struct {
 
 type pricesResponse struct {
 	Success bool     `json:"success"`
-	Items   []*price `json:"items"`
+	Items   []*Price `json:"items"`
 }
 
-type price struct {
+// Price is an item of Prices
+type Price struct {
 	Name  string `json:"name"`
 	Min   int64  `json:"min"`
 	Avg   int64  `json:"avg"`
@@ -60,7 +62,7 @@ type accountHistory struct {
 	SendUntil time.Time   `json:"send_until"`
 	Reason    interface{} `json:"reason"`
 	ID        int64       `json:"id"`
-	ItemID    string      `json:"item_id"`
+	ItemID    ItemID      `json:"item_id"`
 	Image     string      `json:"image"`
 	Price     int64       `json:"price"`
 	Name      string      `json:"name"`
@@ -87,11 +89,11 @@ type tradesReadyToTransferP2P struct {
 	SendUntil    string `json:"send_until"`
 	Items        []*struct {
 		ID         int64  `json:"id"`
-		ItemID     string `json:"item_id"`
+		ItemID     ItemID `json:"item_id"`
 		GiveAmount int64  `json:"give_amount"`
 		Image      string `json:"image"`
 		Price      int64  `json:"price"`
-		Game       string `json:"game"`
+		Game       Game   `json:"game"`
 		Name       string `json:"name"`
 		Status     int64  `json:"status"`
 	} `json:"items"`
@@ -103,7 +105,7 @@ type itemAvailableResponse struct {
 }
 
 type itemAvailable struct {
-	ItemID  string `json:"item_id"`
+	ItemID  ItemID `json:"item_id"`
 	Selling bool   `json:"selling"`
 	Price   int64  `json:"price"`
 	Name    string `json:"name"`
@@ -112,11 +114,12 @@ type itemAvailable struct {
 
 type pricesFilterResponse struct {
 	Success bool           `json:"success"`
-	Items   []*priceFilter `json:"items"`
+	Items   []*PriceFilter `json:"items"`
 }
 
-type priceFilter struct {
-	ItemID     string  `json:"item_id"`
+// PriceFilter is an item of PricesFilter
+type PriceFilter struct {
+	ItemID     ItemID  `json:"item_id"`
 	Brand      string  `json:"brand"`
 	Image      string  `json:"image"`
 	Price      int64   `json:"price"`
@@ -142,18 +145,18 @@ type sellEditResponse struct {
 }
 
 type sellEditItem struct {
-	ItemID string `json:"item_id"`
+	ItemID ItemID `json:"item_id"`
 	Price  string `json:"price"`
 }
 
 type sellFailedItem struct {
-	ItemID int    `json:"item_id"`
+	ItemID ItemID `json:"item_id"`
 	Msg    string `json:"msg"`
 }
 
 type sellRemovedItem struct {
 	Price  string `json:"price"`
-	ItemID int    `json:"item_id"`
+	ItemID ItemID `json:"item_id"`
 }
 
 type sellResponse struct {
@@ -166,14 +169,14 @@ type sellResponse struct {
 type listedItem struct {
 	Name     string `json:"name"`
 	Price    int    `json:"price"`
-	ItemID   int64  `json:"item_id"`
+	ItemID   ItemID `json:"item_id"`
 	Position int    `json:"position"`
 }
 
 type failedItem struct {
 	Name   string `json:"name"`
 	Price  int    `json:"price"`
-	ItemID int64  `json:"item_id"`
+	ItemID ItemID `json:"item_id"`
 	Msg    string `json:"msg"`
 }
 
@@ -183,7 +186,7 @@ type sellOrdersResponse struct {
 }
 
 type sellOrders struct {
-	ItemID     int64     `json:"item_id,int64"`
+	ItemID     ItemID    `json:"item_id"`
 	Price      int       `json:"price"`
 	Date       time.Time `json:"date"`
 	Position   int       `json:"position"`
@@ -202,7 +205,7 @@ type sellItemsResponse struct {
 }
 
 type sellItems struct {
-	ItemID     int64  `json:"item_id"`
+	ItemID     ItemID `json:"item_id"`
 	Name       string `json:"name"`
 	Type       string `json:"type"`
 	SteamPrice struct {
@@ -223,13 +226,13 @@ type priceName struct {
 	Name   string `json:"name"`
 	Price  int64  `json:"price"`
 	Image  string `json:"image"`
-	ItemID string `json:"item_id"`
+	ItemID ItemID `json:"item_id"`
 }
 
 type sellRemoveResponse struct {
-	Success bool     `json:"success"`
-	Count   int      `json:"count"`
-	Removed *[]int64 `json:"removed"`
+	Success bool      `json:"success"`
+	Count   int       `json:"count"`
+	Removed *[]ItemID `json:"removed"`
 }
 
 type sellRemoveAllResponse struct {
@@ -26,13 +26,13 @@ const (
 	steamCheckManyProjectId = defaultURL + "check-many-project-id?"
 )
 
-// appId: 730,570
-func (s *Session) PricesSteam(appId uint64) ([]*steamItem, error) {
+// game: GameCSGO, GameDota2
+func (s *Session) PricesSteam(game Game) ([]*SteamItem, error) {
 	bodyRequest := url.Values{
 		"api":  {s.WaxpeerApiKey},
-		"game": {strconv.FormatUint(appId, 10)},
+		"game": {game.appIDParam()},
 	}
-	b, err := Get(steamGetSteamItems + bodyRequest.Encode())
+	b, err := s.get(steamGetSteamItems, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +59,7 @@ func (s *Session) CheckTradelink(tradelink string) (*checkTradelinkResponse, err
 	if err != nil {
 		return nil, err
 	}
-	b, err := Post(steamCheckTradelink+bodyRequest.Encode(), bodyRequestJson)
+	b, err := s.post(steamCheckTradelink, bodyRequest, bodyRequestJson)
 	if err != nil {
 		return nil, err
 	}
@@ -74,26 +74,15 @@ func (s *Session) CheckTradelink(tradelink string) (*checkTradelinkResponse, err
 }
 
 type PricesConfig struct {
-	Game     string // csgo, dota2
+	Game     Game // GameCSGO, GameDota2
 	MinPrice uint64
 	MaxPrice uint64
 	Search   string // search by name ex: 'hardened'.
 }
 
 // get lowest price and amount of items
-func (s *Session) Prices(c PricesConfig) ([]*price, error) {
-	bodyRequest := url.Values{
-		"api":    {s.WaxpeerApiKey},
-		"game":   {c.Game},
-		"search": {c.Search},
-	}
-	if c.MaxPrice != 0 {
-		bodyRequest.Add("max_price", strconv.FormatUint(c.MaxPrice, 10))
-	}
-	if c.MinPrice != 0 {
-		bodyRequest.Add("min_price", strconv.FormatUint(c.MinPrice, 10))
-	}
-	b, err := Get(steamPrices + bodyRequest.Encode())
+func (s *Session) Prices(c PricesConfig) ([]*Price, error) {
+	b, err := s.get(steamPrices, c.query(s))
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +96,21 @@ func (s *Session) Prices(c PricesConfig) ([]*price, error) {
 	return body.Items, nil
 }
 
+func (c PricesConfig) query(s *Session) url.Values {
+	bodyRequest := url.Values{
+		"api":    {s.WaxpeerApiKey},
+		"game":   {c.Game.String()},
+		"search": {c.Search},
+	}
+	if c.MaxPrice != 0 {
+		bodyRequest.Add("max_price", strconv.FormatUint(c.MaxPrice, 10))
+	}
+	if c.MinPrice != 0 {
+		bodyRequest.Add("min_price", strconv.FormatUint(c.MinPrice, 10))
+	}
+	return bodyRequest
+}
+
 // fetch trades that need to be sent, we recommend sending a trade once. You should be making this request at least every minute in order to be online
 func AccountReadyToTransferP2P(SteamApiKey string) ([]*tradesReadyToTransferP2P, error) {
 	bodyRequest := url.Values{
@@ -127,7 +131,7 @@ func AccountReadyToTransferP2P(SteamApiKey string) ([]*tradesReadyToTransferP2P,
 }
 
 // fetches items based on the item_id passed in query
-func (s *Session) ItemAvailable(idArray *[]uint64) ([]*itemAvailable, error) {
+func (s *Session) ItemAvailable(idArray *[]ItemID) ([]*itemAvailable, error) {
 	if len(*idArray) > 100 {
 		return nil, max100Elements
 	}
@@ -135,9 +139,9 @@ func (s *Session) ItemAvailable(idArray *[]uint64) ([]*itemAvailable, error) {
 		"api": {s.WaxpeerApiKey},
 	}
 	for _, id := range *idArray {
-		bodyRequest.Add("item_id", strconv.FormatUint(id, 10))
+		bodyRequest.Add("item_id", id.String())
 	}
-	b, err := Get(steamCheckAvailability + bodyRequest.Encode())
+	b, err := s.get(steamCheckAvailability, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -152,35 +156,53 @@ func (s *Session) ItemAvailable(idArray *[]uint64) ([]*itemAvailable, error) {
 }
 
 type PricesFilterConfig struct {
-	Skip     uint64 // how many items to skip, to skip previous items
-	Auto     bool   // either true or false. If you pass true, then it will return items instantly available for withdrawing
-	Search   string // search items by name, ex: asiimov
-	Brand    string // item category, ex: key,rifle,knife
-	Order    string // ex: desc or asc
-	OrderBy  string // ex: price,profit,best_deals
-	Exterior string // ex: FN, MW,FT,WW,BS
-	By       string // only fetch items from certain users by passing UUID from their profile page
-	Limit    uint64 // how many items we would like to fetch
-	Sort     string // ex: profit, desc, asc, best_deals
-	MaxPrice uint64 // 1$ = 1000
-	MinPrice uint64 // 1$ = 1000
-	Discount uint64 // if you pass this parameter for example 10, then it will show items with discount 10% or higher
-	Minified bool   // if you pass this you will receive additional info like float
-	Game     string // ex: csgo, dota2
+	Skip     uint64   // how many items to skip, to skip previous items
+	Auto     bool     // either true or false. If you pass true, then it will return items instantly available for withdrawing
+	Search   string   // search items by name, ex: asiimov
+	Brand    Brand    // item category, ex: BrandKey, BrandRifle, BrandKnife
+	Order    Order    // OrderDesc or OrderAsc
+	OrderBy  OrderBy  // ex: OrderByPrice, OrderByProfit, OrderByBestDeals
+	Exterior Exterior // ex: ExteriorFN, ExteriorMW, ExteriorFT, ExteriorWW, ExteriorBS
+	By       string   // only fetch items from certain users by passing UUID from their profile page
+	Limit    uint64   // how many items we would like to fetch
+	Sort     Sort     // ex: SortProfit, SortDesc, SortAsc, SortBestDeals
+	MaxPrice uint64   // 1$ = 1000
+	MinPrice uint64   // 1$ = 1000
+	Discount uint64   // if you pass this parameter for example 10, then it will show items with discount 10% or higher
+	Minified bool     // if you pass this you will receive additional info like float
+	Game     Game     // ex: GameCSGO, GameDota2
 }
 
-// fetches items based on the game you pass as a query
-func (s *Session) PricesFilter(c PricesFilterConfig) ([]*priceFilter, error) {
+// fetches items based on the game you pass as a query, unknown filter values are rejected before the request
+func (s *Session) PricesFilter(c PricesFilterConfig) ([]*PriceFilter, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	b, err := s.get(steamGetItemsList, c.query(s))
+	if err != nil {
+		return nil, err
+	}
+	var body pricesFilterResponse
+	if err = json.Unmarshal(*b, &body); err != nil {
+		return nil, err
+	}
+	if body.Success != true {
+		return nil, wrongApiKey
+	}
+	return body.Items, nil
+}
+
+func (c PricesFilterConfig) query(s *Session) url.Values {
 	bodyRequest := url.Values{
 		"api":      {s.WaxpeerApiKey},
 		"search":   {c.Search},
-		"brand":    {c.Brand},
-		"order":    {c.Order},
-		"order_by": {c.OrderBy},
-		"exterior": {c.Exterior},
+		"brand":    {string(c.Brand)},
+		"order":    {string(c.Order)},
+		"order_by": {string(c.OrderBy)},
+		"exterior": {string(c.Exterior)},
 		"by":       {c.By},
-		"sort":     {c.Sort},
-		"game":     {c.Game},
+		"sort":     {string(c.Sort)},
+		"game":     {c.Game.String()},
 	}
 	if c.MaxPrice != 0 {
 		bodyRequest.Add("max_price", strconv.FormatUint(c.MaxPrice, 10))
@@ -188,6 +210,9 @@ func (s *Session) PricesFilter(c PricesFilterConfig) ([]*priceFilter, error) {
 	if c.MinPrice != 0 {
 		bodyRequest.Add("min_price", strconv.FormatUint(c.MinPrice, 10))
 	}
+	if c.Skip != 0 {
+		bodyRequest.Add("skip", strconv.FormatUint(c.Skip, 10))
+	}
 	if c.Limit != 0 {
 		bodyRequest.Add("limit", strconv.FormatUint(c.Limit, 10))
 	}
@@ -200,18 +225,7 @@ func (s *Session) PricesFilter(c PricesFilterConfig) ([]*priceFilter, error) {
 	if c.Minified {
 		bodyRequest.Add("minified", "1")
 	}
-	b, err := Get(steamGetItemsList + bodyRequest.Encode())
-	if err != nil {
-		return nil, err
-	}
-	var body pricesFilterResponse
-	if err = json.Unmarshal(*b, &body); err != nil {
-		return nil, err
-	}
-	if body.Success != true {
-		return nil, wrongApiKey
-	}
-	return body.Items, nil
+	return bodyRequest
 }
 
 // fetch my inventory
@@ -219,7 +233,7 @@ func (s *Session) AccountReloadInventory() error {
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
-	b, err := Get(steamFetchMyInventory + bodyRequest.Encode())
+	b, err := s.get(steamFetchMyInventory, bodyRequest)
 	if err != nil {
 		return err
 	}
@@ -238,8 +252,8 @@ type requestItem struct {
 }
 
 type SellItemConfig struct {
-	ItemID int64 `json:"item_id"`
-	Price  int64 `json:"price"`
+	ItemID ItemID `json:"item_id"`
+	Price  int64  `json:"price"`
 }
 
 // edit price for listed items
@@ -247,6 +261,11 @@ func (s *Session) SellEdit(c *[]SellItemConfig) (*sellEditResponse, error) {
 	if len(*c) > 50 {
 		return nil, max50Elements
 	}
+	if s.PriceGuard != nil {
+		if err := s.PriceGuard.checkSell(s, *c, true); err != nil {
+			return nil, err
+		}
+	}
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
@@ -254,7 +273,7 @@ func (s *Session) SellEdit(c *[]SellItemConfig) (*sellEditResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	b, err := Post(steamEditItem+bodyRequest.Encode(), bodyRequestJson)
+	b, err := s.post(steamEditItem, bodyRequest, bodyRequestJson)
 	if err != nil {
 		return nil, err
 	}
@@ -273,6 +292,11 @@ func (s *Session) Sell(c *[]SellItemConfig) (*sellResponse, error) {
 	if len(*c) > 50 {
 		return nil, max50Elements
 	}
+	if s.PriceGuard != nil {
+		if err := s.PriceGuard.checkSell(s, *c, false); err != nil {
+			return nil, err
+		}
+	}
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
@@ -280,7 +304,7 @@ func (s *Session) Sell(c *[]SellItemConfig) (*sellResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	b, err := Post(steamListItem+bodyRequest.Encode(), bodyRequestJson)
+	b, err := s.post(steamListItem, bodyRequest, bodyRequestJson)
 	if err != nil {
 		return nil, err
 	}
@@ -299,7 +323,7 @@ func (s *Session) SellOrders() ([]*sellOrders, error) {
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
-	b, err := Get(steamListItem + bodyRequest.Encode())
+	b, err := s.get(steamListItem, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -315,7 +339,7 @@ func (s *Session) SellOrders() ([]*sellOrders, error) {
 
 type SellItemsConfig struct {
 	Skip uint64 // skip amount of items (Pagination) since default gives only an array with a length of 30
-	Game uint64 // ex: 730
+	Game Game   // ex: GameCSGO
 }
 
 //get items that you can list for sale
@@ -323,9 +347,9 @@ func (s *Session) SellItems(c SellItemsConfig) ([]*sellItems, error) {
 	bodyRequest := url.Values{
 		"api":  {s.WaxpeerApiKey},
 		"skip": {strconv.FormatUint(c.Skip, 10)},
-		"game": {strconv.FormatUint(c.Game, 10)},
+		"game": {c.Game.appIDParam()},
 	}
-	b, err := Get(steamGetInventory + bodyRequest.Encode())
+	b, err := s.get(steamGetInventory, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -350,7 +374,7 @@ func (s *Session) PricesName(nameArray *[]string) ([]*priceName, error) {
 	for _, name := range *nameArray {
 		bodyRequest.Add("names", name)
 	}
-	b, err := Get(steamSearchItemsByName + bodyRequest.Encode())
+	b, err := s.get(steamSearchItemsByName, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -365,47 +389,47 @@ func (s *Session) PricesName(nameArray *[]string) ([]*priceName, error) {
 }
 
 //remove items
-func (s *Session) SellRemove(idArray *[]uint64) error {
+func (s *Session) SellRemove(idArray *[]ItemID) (*sellRemoveResponse, error) {
 	if len(*idArray) > 1000 {
-		return max1000Elements
+		return nil, max1000Elements
 	}
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
 	for _, id := range *idArray {
-		bodyRequest.Add("id", strconv.FormatUint(id, 10))
+		bodyRequest.Add("id", id.String())
 	}
-	b, err := Get(steamRemoveItems + bodyRequest.Encode())
+	b, err := s.get(steamRemoveItems, bodyRequest)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var body sellRemoveResponse
 	if err = json.Unmarshal(*b, &body); err != nil {
-		return err
+		return nil, err
 	}
 	if body.Success != true {
-		return wrongApiKey
+		return nil, wrongApiKey
 	}
-	return nil
+	return &body, nil
 }
 
 //remove all items
-func (s *Session) SellRemoveAll() error {
+func (s *Session) SellRemoveAll() (*sellRemoveAllResponse, error) {
 	bodyRequest := url.Values{
 		"api": {s.WaxpeerApiKey},
 	}
-	b, err := Get(steamRemoveAllItems + bodyRequest.Encode())
+	b, err := s.get(steamRemoveAllItems, bodyRequest)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var body sellRemoveAllResponse
 	if err = json.Unmarshal(*b, &body); err != nil {
-		return err
+		return nil, err
 	}
 	if body.Success != true {
-		return wrongApiKey
+		return nil, wrongApiKey
 	}
-	return nil
+	return &body, nil
 }
 
 // account history by id
@@ -419,7 +443,7 @@ func (s *Session) AccountHistoryID(idArray *[]string) ([]*accountHistoryID, erro
 	for _, id := range *idArray {
 		bodyRequest.Add("id", id)
 	}
-	b, err := Get(steamCheckManyProjectId + bodyRequest.Encode())
+	b, err := s.get(steamCheckManyProjectId, bodyRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -443,6 +467,11 @@ type BuyNameConfig struct {
 
 // buy item and send to specific tradelink
 func (s *Session) BuyName(c BuyNameConfig) error {
+	if s.PriceGuard != nil {
+		if err := s.PriceGuard.checkBuy(s, 0, c.Name, int64(c.Price)); err != nil {
+			return err
+		}
+	}
 	bodyRequest := url.Values{
 		"api":        {s.WaxpeerApiKey},
 		"project_id": {c.ProjectId},
@@ -451,7 +480,7 @@ func (s *Session) BuyName(c BuyNameConfig) error {
 		"price":      {strconv.FormatUint(c.Price, 10)},
 		"partner":    {c.Partner},
 	}
-	b, err := Get(steamBuyOneP2PName + bodyRequest.Encode())
+	b, err := s.get(steamBuyOneP2PName, bodyRequest)
 	if err != nil {
 		return err
 	}
@@ -467,7 +496,7 @@ func (s *Session) BuyName(c BuyNameConfig) error {
 
 type BuyIDConfig struct {
 	ProjectId string // your unique ID, max 50 symbols, it will be possible to track your trade
-	ItemId    uint64 // item id from fetching our items
+	ItemId    ItemID // item id from fetching our items
 	Token     string // token parameter from steam tradelink
 	Price     uint64 // item price | 1$=1000
 	Partner   string // partner parameter from steam tradelink
@@ -475,15 +504,20 @@ type BuyIDConfig struct {
 
 // buy item and send to specific tradelink
 func (s *Session) BuyID(c BuyIDConfig) error {
+	if s.PriceGuard != nil {
+		if err := s.PriceGuard.checkBuy(s, c.ItemId, "", int64(c.Price)); err != nil {
+			return err
+		}
+	}
 	bodyRequest := url.Values{
 		"api":        {s.WaxpeerApiKey},
 		"project_id": {c.ProjectId},
 		"token":      {c.Token},
 		"partner":    {c.Partner},
-		"item_id":    {strconv.FormatUint(c.ItemId, 10)},
+		"item_id":    {c.ItemId.String()},
 		"price":      {strconv.FormatUint(c.Price, 10)},
 	}
-	b, err := Get(steamBuyOneP2P + bodyRequest.Encode())
+	b, err := s.get(steamBuyOneP2P, bodyRequest)
 	if err != nil {
 		return err
 	}
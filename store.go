@@ -0,0 +1,84 @@
+package waxpeer
+
+import (
+	"sort"
+	"sync"
+)
+
+// Store is a key-value storage used by Archive and HistorySyncer.
+// Keys of a bucket are iterated in ascending byte order
+type Store interface {
+	Put(bucket, key string, value []byte) error
+	Get(bucket, key string) ([]byte, error) // returns nil, nil if the key does not exist
+	Delete(bucket, key string) error
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	Clear(bucket string) error
+	Close() error
+}
+
+// MemoryStore keeps data in memory, it is lost when the program exits
+type MemoryStore struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]map[string][]byte)}
+}
+
+func (m *MemoryStore) Put(bucket, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.buckets[bucket]
+	if !ok {
+		b = make(map[string][]byte)
+		m.buckets[bucket] = b
+	}
+	b[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (m *MemoryStore) Get(bucket, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.buckets[bucket][key], nil
+}
+
+func (m *MemoryStore) Delete(bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.buckets[bucket], key)
+	return nil
+}
+
+func (m *MemoryStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	m.mu.RLock()
+	b := m.buckets[bucket]
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	m.mu.RUnlock()
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, _ := m.Get(bucket, k)
+		if v == nil {
+			continue
+		}
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) Clear(bucket string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.buckets, bucket)
+	return nil
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,172 @@
+package waxpeer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Stop can be returned by the callbacks of the Each methods to stop decoding early
+var Stop = errors.New("stop")
+
+// PricesEach is Prices that decodes items one by one while the response is received and passes them to fn
+// instead of building the whole slice, fn may return Stop to stop early
+func (s *Session) PricesEach(c PricesConfig, fn func(item *Price) error) error {
+	return s.stream(steamPrices, c.query(s), func(dec *json.Decoder) error {
+		var item Price
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		return fn(&item)
+	})
+}
+
+// PricesSteamEach is PricesSteam that passes items to fn one by one, fn may return Stop to stop early
+func (s *Session) PricesSteamEach(game Game, fn func(item *SteamItem) error) error {
+	bodyRequest := url.Values{
+		"api":  {s.WaxpeerApiKey},
+		"game": {game.appIDParam()},
+	}
+	return s.stream(steamGetSteamItems, bodyRequest, func(dec *json.Decoder) error {
+		var item SteamItem
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		return fn(&item)
+	})
+}
+
+// PricesFilterEach is PricesFilter that passes items to fn one by one, fn may return Stop to stop early
+func (s *Session) PricesFilterEach(c PricesFilterConfig, fn func(item *PriceFilter) error) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return s.stream(steamGetItemsList, c.query(s), func(dec *json.Decoder) error {
+		var item PriceFilter
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		return fn(&item)
+	})
+}
+
+// stream sends a GET request and decodes the items of the response with next while the body is received.
+// Middleware and the cache need whole responses, with them the buffered body is decoded instead
+func (s *Session) stream(endpoint string, params url.Values, next func(dec *json.Decoder) error) error {
+	name := endpointName(endpoint)
+	if len(s.middleware) != 0 || s.cache != nil && s.cache.TTL[name] > 0 {
+		b, err := s.get(endpoint, params)
+		if err != nil {
+			return err
+		}
+		return decodeItems(bytes.NewReader(*b), next, nil)
+	}
+	ctx := s.context()
+	var decodeErr error
+	resp, err := s.withKey(ctx, endpoint, func(key string) (*Response, error) {
+		req := s.newRequest("GET", endpoint, params, nil, key)
+		req.stream = func(r io.Reader, resp *Response) {
+			decodeErr = decodeItems(r, next, resp)
+		}
+		return s.roundTrip(ctx, req)
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status %d", name, resp.StatusCode)
+	}
+	return decodeErr
+}
+
+// decodeItems walks the response object and calls next for every element of the "items" array,
+// next decodes one element from dec. Other fields are skipped, a response without success: true returns wrongApiKey.
+// success and msg are recorded on resp if it is not nil
+func decodeItems(r io.Reader, next func(dec *json.Decoder) error, resp *Response) error {
+	var success, found bool
+	var msg string
+	if resp != nil {
+		defer func() {
+			if found {
+				resp.setOutcome(success, msg)
+			}
+		}()
+	}
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case "success":
+			if err = dec.Decode(&success); err != nil {
+				return err
+			}
+			found = true
+		case "msg":
+			var m interface{}
+			if err = dec.Decode(&m); err != nil {
+				return err
+			}
+			msg, _ = m.(string)
+		case "items":
+			if found && !success {
+				var skip json.RawMessage
+				if err = dec.Decode(&skip); err != nil {
+					return err
+				}
+				continue
+			}
+			if err = decodeArray(dec, next); err != nil {
+				if err == Stop {
+					return nil
+				}
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	if !success {
+		return wrongApiKey
+	}
+	return nil
+}
+
+func decodeArray(dec *json.Decoder, next func(dec *json.Decoder) error) error {
+	t, err := dec.Token()
+	if err != nil || t == nil {
+		return err
+	}
+	if t != json.Delim('[') {
+		return fmt.Errorf("items: expected array, got %v", t)
+	}
+	for dec.More() {
+		if err = next(dec); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("expected %v, got %v", delim, t)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package waxpeer
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// fakeWaxpeer answers every request to api.waxpeer.com with body, gzipped if gzip is set and the request accepts it.
+// It returns the number of bytes the clients have read from the connections so far
+func fakeWaxpeer(tb testing.TB, body []byte, gzip bool) func() int64 {
+	compressed := fasthttp.AppendGzipBytes(nil, body)
+	return fakeServer(tb, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		if gzip && ctx.Request.Header.HasAcceptEncoding("gzip") {
+			ctx.Response.Header.Set("Content-Encoding", "gzip")
+			ctx.SetBody(compressed)
+			return
+		}
+		ctx.SetBody(body)
+	})
+}
+
+// fakeServer sends the requests of the package to handler instead of api.waxpeer.com until the test ends
+func fakeServer(tb testing.TB, handler fasthttp.RequestHandler) func() int64 {
+	cert, key, err := fasthttp.GenerateTestCertificate("api.waxpeer.com")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	ln := fasthttputil.NewInmemoryListener()
+	server := &fasthttp.Server{Handler: handler}
+	go server.ServeTLSEmbed(ln, cert, key)
+
+	var read int64
+	dial := func(string) (net.Conn, error) {
+		conn, err := ln.Dial()
+		if err != nil {
+			return nil, err
+		}
+		return &countingConn{Conn: conn, read: &read}, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	oldClient, oldStream := client, streamClient
+	client = &fasthttp.Client{Dial: dial, TLSConfig: tlsConfig}
+	streamClient = &fasthttp.Client{Dial: dial, TLSConfig: tlsConfig, StreamResponseBody: true, MaxResponseBodySize: streamBuffer}
+	tb.Cleanup(func() {
+		client, streamClient = oldClient, oldStream
+		ln.Close()
+	})
+	return func() int64 { return atomic.LoadInt64(&read) }
+}
+
+type countingConn struct {
+	net.Conn
+	read *int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(c.read, int64(n))
+	return n, err
+}
+
+func pricesBody(tb testing.TB, n int) []byte {
+	items := make([]*Price, n)
+	for i := range items {
+		items[i] = &Price{Name: fmt.Sprintf("AK-47 | Redline (Field-Tested) %d", i), Min: 1000, Avg: 1200, Max: 1500, Count: 10}
+	}
+	b, err := json.Marshal(pricesResponse{Success: true, Items: items})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return b
+}
+
+func TestPricesEach(t *testing.T) {
+	for _, gzip := range []bool{false, true} {
+		fakeWaxpeer(t, pricesBody(t, 5000), gzip)
+		s := CreateSession("key")
+		prices, err := s.Prices(PricesConfig{Game: GameCSGO})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var i int
+		err = s.PricesEach(PricesConfig{Game: GameCSGO}, func(item *Price) error {
+			if *item != *prices[i] {
+				t.Fatalf("gzip %v: item %d is %+v, want %+v", gzip, i, item, prices[i])
+			}
+			i++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != len(prices) {
+			t.Fatalf("gzip %v: got %d items, want %d", gzip, i, len(prices))
+		}
+	}
+}
+
+type kindMetrics struct{ kinds []string }
+
+func (m *kindMetrics) ObserveRequest(endpoint string, d time.Duration, kind string) {
+	m.kinds = append(m.kinds, kind)
+}
+func (m *kindMetrics) ObserveRateLimitWait(endpoint string, wait time.Duration) {}
+func (m *kindMetrics) ObserveRetry(endpoint string)                             {}
+
+func TestPricesEachFailure(t *testing.T) {
+	for _, c := range []struct {
+		body     string
+		rejected bool
+	}{
+		{`{"success": false, "msg": "too many requests"}`, false},
+		{`{"success": false, "msg": "wrong api key"}`, true},
+	} {
+		fakeServer(t, func(ctx *fasthttp.RequestCtx) { ctx.SetBodyString(c.body) })
+		var hooks int
+		m := &kindMetrics{}
+		s := CreateSession("key", WithMetrics(m), WithInvalidKeyHook(func(ctx context.Context, endpoint string) { hooks++ }))
+		err := s.PricesEach(PricesConfig{Game: GameCSGO}, func(item *Price) error { return nil })
+		if err != wrongApiKey {
+			t.Fatalf("%s: got %v, want wrongApiKey", c.body, err)
+		}
+		if c.rejected != (hooks == 1) {
+			t.Fatalf("%s: invalid key hook ran %d times", c.body, hooks)
+		}
+		if len(m.kinds) != 1 || m.kinds[0] != ErrorKindApi {
+			t.Fatalf("%s: error kinds %v, want [%s]", c.body, m.kinds, ErrorKindApi)
+		}
+	}
+}
+
+func BenchmarkPrices(b *testing.B) {
+	body := pricesBody(b, 20000)
+	fakeWaxpeer(b, body, false)
+	s := CreateSession("key")
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Prices(PricesConfig{Game: GameCSGO}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPricesEach(b *testing.B) {
+	body := pricesBody(b, 20000)
+	fakeWaxpeer(b, body, false)
+	s := CreateSession("key")
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := s.PricesEach(PricesConfig{Game: GameCSGO}, func(item *Price) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package waxpeer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ItemID is the id of an item on waxpeer, decodes from both json strings and numbers
+type ItemID uint64
+
+func (id ItemID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func (id ItemID) MarshalJSON() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *ItemID) UnmarshalJSON(b []byte) error {
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		*id = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return err
+	}
+	*id = ItemID(v)
+	return nil
+}
+
+// ParseItemID parses an item id from a string
+func ParseItemID(s string) (ItemID, error) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	return ItemID(v), err
+}
+
+// Game is a game supported by waxpeer, some endpoints take the slug and others the steam app id
+type Game string
+
+const (
+	GameCSGO  Game = "csgo"
+	GameDota2 Game = "dota2"
+	GameRust  Game = "rust"
+	GameTF2   Game = "tf2"
+)
+
+var gameAppIDs = map[Game]uint64{
+	GameCSGO:  730,
+	GameDota2: 570,
+	GameRust:  252490,
+	GameTF2:   440,
+}
+
+// GameByAppID returns the game with the steam app id, games unknown to this package keep the id as the slug
+func GameByAppID(appID uint64) Game {
+	for g, id := range gameAppIDs {
+		if id == appID {
+			return g
+		}
+	}
+	return Game(strconv.FormatUint(appID, 10))
+}
+
+// ParseGame parses a slug or a steam app id, ex: csgo, 730
+func ParseGame(s string) (Game, error) {
+	if _, ok := gameAppIDs[Game(s)]; ok {
+		return Game(s), nil
+	}
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("unknown game %q", s)
+	}
+	return GameByAppID(id), nil
+}
+
+func (g Game) String() string {
+	return string(g)
+}
+
+// AppID returns the steam app id of the game, 0 - unknown game
+func (g Game) AppID() uint64 {
+	if id, ok := gameAppIDs[g]; ok {
+		return id
+	}
+	id, _ := strconv.ParseUint(string(g), 10, 64)
+	return id
+}
+
+// appIDParam formats the app id for endpoints that take it, empty for the zero Game
+func (g Game) appIDParam() string {
+	if g == "" {
+		return ""
+	}
+	return strconv.FormatUint(g.AppID(), 10)
+}
+
+// UnmarshalJSON accepts both slugs and app ids
+func (g *Game) UnmarshalJSON(b []byte) error {
+	if len(b) == 0 || string(b) == "null" {
+		*g = ""
+		return nil
+	}
+	if b[0] != '"' {
+		id, err := strconv.ParseUint(string(b), 10, 64)
+		if err != nil {
+			return err
+		}
+		*g = GameByAppID(id)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*g = ""
+		return nil
+	}
+	game, err := ParseGame(s)
+	if err != nil {
+		game = Game(s)
+	}
+	*g = game
+	return nil
+}